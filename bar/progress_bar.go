@@ -1,4 +1,6 @@
-// Package bar provides a fake progress bar to be compatible with the Progressor interface of package schema
+// Package bar provides a fake progress bar to be compatible with the former Progressor interface of package schema.
+//
+// Deprecated: the progress bar of package schema is a subscriber now, see schema.WithProgressBar().
 package bar
 
 import "github.com/cheggaaa/pb/v3"
@@ -0,0 +1,127 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/rebel-l/schema/store"
+)
+
+var (
+	// ErrBaselineNotEmpty is used if Baseline() is called for a database which has already logged script executions
+	ErrBaselineNotEmpty = errors.New("baseline needs a database without logged script executions")
+)
+
+// Baseline adopts an existing database whose schema was created without this package, e.g. by applying the scripts
+// manually. It creates the tables needed by this package and records all scripts up to the target (including it) as
+// applied with status baseline without executing them, so following calls of Upgrade() apply only newer scripts.
+// A path to the sql scripts needs to be provided, the target is matched in the same way as by MigrateTo().
+// The version of your application can be provided too, use empty string to ignore it.
+func (s *Schema) Baseline(path string, upToScript string, version string) error {
+	return s.withLock(context.Background(), func(ctx context.Context) error {
+		return s.baseline(ctx, dirSource(path), upToScript, version)
+	})
+}
+
+// BaselineFS does the same as Baseline but takes the scripts from the given file system, see UpgradeFS().
+func (s *Schema) BaselineFS(fsys fs.FS, upToScript string, version string) error {
+	return s.withLock(context.Background(), func(ctx context.Context) error {
+		return s.baseline(ctx, fsSource(fsys), upToScript, version)
+	})
+}
+
+func (s *Schema) baseline(ctx context.Context, src source, upToScript string, version string) error {
+	if err := s.init(); err != nil {
+		return err
+	}
+
+	executedScripts, err := s.scripter.GetAllContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if executedScripts.Len() > 0 {
+		return ErrBaselineNotEmpty
+	}
+
+	scripts, err := src.scan(s.migrations, s.scanOptions)
+	if err != nil {
+		return err
+	}
+
+	scripts = versioned(scripts)
+
+	pos, err := findTarget(scripts, upToScript)
+	if err != nil {
+		return err
+	}
+
+	entries := make([]*store.SchemaScript, 0, pos+1)
+
+	for _, sc := range scripts[:pos+1] {
+		checksum, err := sc.checksum()
+		if err != nil {
+			return err
+		}
+
+		entry := store.NewSchemaScriptBaseline(sc.name, version)
+		entry.Checksum = checksum
+		entries = append(entries, entry)
+	}
+
+	return s.recordBaseline(ctx, entries)
+}
+
+// recordBaseline adds all entries or none of them, so a failed baseline can be retried. The entries are added in one
+// transaction if the database supports it, otherwise the entries added already are removed again on error.
+func (s *Schema) recordBaseline(ctx context.Context, entries []*store.SchemaScript) error {
+	db, ok := s.db.(store.Transactioner)
+	if !ok {
+		return s.recordBaselineWithoutTransaction(ctx, entries)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err = s.scripter.AddWithContext(ctx, tx, entry); err != nil {
+			err = fmt.Errorf("failed to record baseline of script %s: %w", entry.ScriptName, err)
+
+			// a transaction is rolled back automatically if the context is done
+			if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+				return fmt.Errorf("%w, rollback failed: %v", err, rollbackErr)
+			}
+
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *Schema) recordBaselineWithoutTransaction(ctx context.Context, entries []*store.SchemaScript) error {
+	for i, entry := range entries {
+		err := s.scripter.AddContext(ctx, entry)
+		if err == nil {
+			continue
+		}
+
+		err = fmt.Errorf("failed to record baseline of script %s: %w", entry.ScriptName, err)
+
+		// the entries are removed even if the context was cancelled or timed out
+		for _, added := range entries[:i] {
+			if removeErr := s.scripter.RemoveContext(context.WithoutCancel(ctx), added.ScriptName); removeErr != nil {
+				return fmt.Errorf("%w, removal of baseline of script %s failed: %v", err, added.ScriptName, removeErr)
+			}
+		}
+
+		return err
+	}
+
+	return nil
+}
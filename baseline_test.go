@@ -0,0 +1,232 @@
+package schema_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/rebel-l/schema"
+	"github.com/rebel-l/schema/mocks/schema_mock"
+	"github.com/rebel-l/schema/store"
+	"github.com/rebel-l/schema/utils/testdb"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestSchema_Baseline_Happy(t *testing.T) {
+	testCases := []struct {
+		name     string
+		target   string
+		expected []string
+	}{
+		{
+			name:     "name without ending",
+			target:   "1.1_first",
+			expected: []string{"1.1_first.sql"},
+		},
+		{
+			name:     "dotted version",
+			target:   "1.2",
+			expected: []string{"1.1_first.sql", "1.2_second.sql"},
+		},
+		{
+			name:     "dotted version with three parts",
+			target:   "1.2.3",
+			expected: []string{"1.1_first.sql", "1.2_second.sql", "1.2.3_third.sql"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDB := getMockDB(ctrl, false)
+			mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+
+			var actual []string
+
+			mockScripter := schema_mock.NewMockScripter(ctrl)
+			mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(nil, nil)
+			mockScripter.EXPECT().AddContext(gomock.Any(), gomock.Any()).Times(len(testCase.expected)).DoAndReturn(
+				func(_ context.Context, entry *store.SchemaScript) error {
+					actual = append(actual, entry.ScriptName)
+					return nil
+				},
+			)
+
+			s := newSchema(t, mockDB, schema.WithScripter(mockScripter))
+
+			if err := s.Baseline("./testdata/dotted", testCase.target, ""); err != nil {
+				t.Fatalf("Expected no error but got %s", err)
+			}
+
+			if !reflect.DeepEqual(actual, testCase.expected) {
+				t.Errorf("Expected baseline of %v but got %v", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSchema_Baseline_Unhappy(t *testing.T) {
+	testCases := []struct {
+		name     string
+		target   string
+		executed store.SchemaScriptCollection
+		expected error
+	}{
+		{
+			name:     "database not empty",
+			target:   "001",
+			executed: store.SchemaScriptCollection{store.NewSchemaScriptSuccess("001.sql", "")},
+			expected: schema.ErrBaselineNotEmpty,
+		},
+		{
+			name:     "unknown target",
+			target:   "999",
+			expected: schema.ErrUnknownTarget,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDB := getMockDB(ctrl, false)
+			mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+
+			mockScripter := schema_mock.NewMockScripter(ctrl)
+			mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(testCase.executed, nil)
+			mockScripter.EXPECT().AddContext(gomock.Any(), gomock.Any()).Times(0)
+
+			s := newSchema(t, mockDB, schema.WithScripter(mockScripter))
+
+			err := s.Baseline("./testdata/unit", testCase.target, "")
+			if !errors.Is(err, testCase.expected) {
+				t.Errorf("Expected error %s but got %v", testCase.expected, err)
+			}
+		})
+	}
+}
+
+func TestSchema_Baseline_Unhappy_RemovesPartialEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+
+	errAdd := errors.New("insert failed") // nolint: goerr113
+
+	mockScripter := schema_mock.NewMockScripter(ctrl)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(nil, nil)
+	gomock.InOrder(
+		mockScripter.EXPECT().AddContext(gomock.Any(), gomock.Any()).Return(nil),
+		mockScripter.EXPECT().AddContext(gomock.Any(), gomock.Any()).Return(errAdd),
+		mockScripter.EXPECT().RemoveContext(gomock.Any(), "001.sql").Return(nil),
+	)
+
+	s := newSchema(t, mockDB, schema.WithScripter(mockScripter))
+
+	if err := s.Baseline("./testdata/unit", "002", ""); !errors.Is(err, errAdd) {
+		t.Errorf("Expected error %s but got %v", errAdd, err)
+	}
+}
+
+// failingScripter fails to add the given script, all other calls are passed to the embedded Scripter.
+type failingScripter struct {
+	schema.Scripter
+	script string
+}
+
+func (f failingScripter) AddWithContext(ctx context.Context, exec sqlx.Execer, entry *store.SchemaScript) error {
+	if entry.ScriptName == f.script {
+		return errors.New("insert failed") // nolint: goerr113
+	}
+
+	return f.Scripter.AddWithContext(ctx, exec, entry)
+}
+
+func TestSchema_Baseline_Integration_Transaction(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_baseline_transaction.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	scripter := failingScripter{Scripter: store.NewSchemaScriptMapper(db), script: "002_something_new.sql"}
+
+	s := newSchema(t, db, schema.WithScripter(scripter))
+	if err = s.Baseline("./testdata/migrate", "002", "1.0.0"); err == nil {
+		t.Fatal("Expected error on baseline but got nil")
+	}
+
+	data, err := store.NewSchemaScriptMapper(db).GetAll()
+	if err != nil {
+		t.Fatalf("not able get rows from table: %s", err)
+	}
+
+	if len(data) != 0 {
+		t.Errorf("Expected no entries after failed baseline but got %#v", data)
+	}
+
+	s = newSchema(t, db)
+	if err = s.Baseline("./testdata/migrate", "002", "1.0.0"); err != nil {
+		t.Errorf("Expected no error on retry of baseline but got %s", err)
+	}
+}
+
+func TestSchema_Baseline_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_baseline.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	s := newSchema(t, db)
+	if err = s.Baseline("./testdata/migrate", "002", "1.0.0"); err != nil {
+		t.Fatalf("Expected no error on baseline but got %s", err)
+	}
+
+	report, err := s.Status("./testdata/migrate")
+	if err != nil {
+		t.Fatalf("Expected no error on status but got %s", err)
+	}
+
+	if report.Count(schema.StateBaseline) != 2 || report.Count(schema.StatePending) != 1 {
+		t.Errorf("Expected 2 scripts with state baseline and 1 pending but got %#v", report)
+	}
+
+	if err = s.Upgrade("./testdata/migrate", "1.1.0"); err != nil {
+		t.Fatalf("Expected no error on upgrade but got %s", err)
+	}
+
+	var counter []uint32
+	if err = db.Select(&counter, "SELECT count(*) FROM something;"); err == nil {
+		t.Error("Expected that scripts of the baseline are not executed")
+	}
+
+	if err = db.Select(&counter, "SELECT count(*) FROM something_else;"); err != nil {
+		t.Errorf("Expected that newer scripts are executed but got %s", err)
+	}
+
+	if err = s.Baseline("./testdata/migrate", "002", "1.0.0"); !errors.Is(err, schema.ErrBaselineNotEmpty) {
+		t.Errorf("Expected error %s on second baseline but got %v", schema.ErrBaselineNotEmpty, err)
+	}
+}
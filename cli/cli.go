@@ -0,0 +1,296 @@
+// Package cli provides the command line interface of the schema package, see cmd/schema
+package cli
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/rebel-l/schema"
+	"github.com/rebel-l/schema/sqlfile"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-isatty"
+)
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+var (
+	// ErrUsage is used if the command line arguments are invalid
+	ErrUsage = errors.New("invalid usage")
+)
+
+const usage = `Usage: schema [flags] <command> [arguments]
+
+Commands:
+  upgrade      applies all scripts which were not applied yet
+  revert [n]   reverts the last n applied scripts (default 1)
+  revert-all   reverts all applied scripts
+  recreate     reverts all applied scripts and applies them again
+  status       shows the state of each script
+  validate     reports modified, missing and pending scripts
+  repair       removes failed executions, updates checksums of modified scripts and marks missing ones as orphaned
+  create <description>
+               writes a new script with the next number to path, the numbering scheme can be set by -scheme
+
+Settings are taken from the flags, the environment variables SCHEMA_DRIVER, SCHEMA_DSN, SCHEMA_PATH,
+SCHEMA_APP_VERSION, SCHEMA_TABLE and SCHEMA_NAMESPACE or a JSON config file (flag -config or SCHEMA_CONFIG) in this
+order.
+
+Flags:
+`
+
+// Run executes the command line interface with the given arguments (without program name) and returns the exit code.
+// The database driver needs to be registered by the caller, e.g. by importing it in the main package.
+// An interrupt or SIGTERM cancels the running command, see RunContext().
+func Run(args []string, getenv func(string) string, stdout io.Writer, stderr io.Writer) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return RunContext(ctx, args, getenv, stdout, stderr)
+}
+
+// RunContext does the same as Run but cancels upgrade, revert, revert-all and recreate if the context is done. The
+// running script is rolled back and no further script is executed.
+func RunContext(
+	ctx context.Context,
+	args []string,
+	getenv func(string) string,
+	stdout io.Writer,
+	stderr io.Writer,
+) int {
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	flags := &Config{}
+	fs.StringVar(&flags.Driver, "driver", "", "name of the database driver, e.g. sqlite3")
+	fs.StringVar(&flags.DSN, "dsn", "", "data source name to connect to the database")
+	fs.StringVar(&flags.Path, "path", "", "path to the sql scripts")
+	fs.StringVar(&flags.AppVersion, "app-version", "", "version of your application logged with the applied scripts")
+	fs.StringVar(&flags.Table, "table", "", "name of the table logging the script executions, default schema_script")
+	fs.StringVar(&flags.Namespace, "namespace", "", "schema or database containing the tables of this package")
+	configFile := fs.String("config", "", "path to a JSON config file")
+	asJSON := fs.Bool("json", false, "print status as JSON")
+	scheme := fs.String("scheme", sqlfile.SchemeDetect, "numbering scheme of create: sequence or timestamp")
+
+	fs.Usage = func() {
+		_, _ = fmt.Fprint(stderr, usage)
+		fs.PrintDefaults()
+	}
+
+	// flags are allowed before and after the command
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	command := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return exitUsage
+	}
+
+	cfg, err := loadConfig(fs, flags, *configFile, getenv)
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	c := &cli{ctx: ctx, cfg: cfg, args: fs.Args(), json: *asJSON, scheme: *scheme, stdout: stdout}
+
+	err = c.run(command)
+	if errors.Is(err, ErrUsage) {
+		_, _ = fmt.Fprintln(stderr, err)
+		fs.Usage()
+
+		return exitUsage
+	}
+
+	if err != nil {
+		_, _ = fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	return exitOK
+}
+
+type cli struct {
+	ctx    context.Context
+	cfg    *Config
+	args   []string
+	json   bool
+	scheme string
+	stdout io.Writer
+}
+
+func (c *cli) run(command string) error {
+	if command == "create" {
+		return c.create()
+	}
+
+	commands := map[string]func(s *schema.Schema) error{
+		"upgrade":    c.upgrade,
+		"revert":     c.revert,
+		"revert-all": c.revertAll,
+		"recreate":   c.recreate,
+		"status":     c.status,
+		"validate":   c.validate,
+		"repair":     c.repair,
+	}
+
+	f, ok := commands[command]
+	if !ok {
+		return fmt.Errorf("%w: unknown command %s", ErrUsage, command)
+	}
+
+	if c.cfg.Driver == "" || c.cfg.DSN == "" || c.cfg.Path == "" {
+		return fmt.Errorf("%w: driver, dsn and path must be provided", ErrUsage)
+	}
+
+	if !registered(c.cfg.Driver) {
+		return fmt.Errorf(
+			"%w: driver %s is not registered (available: %s), for other drivers build your own main calling "+
+				"cli.Run and importing the driver",
+			ErrUsage, c.cfg.Driver, strings.Join(sql.Drivers(), ", "),
+		)
+	}
+
+	db, err := sqlx.Open(c.cfg.Driver, c.cfg.DSN)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = db.Close()
+	}()
+
+	var opts []schema.Option
+	if c.cfg.Namespace != "" {
+		opts = append(opts, schema.WithNamespace(c.cfg.Namespace))
+	}
+
+	if c.cfg.Table != "" {
+		opts = append(opts, schema.WithTableName(c.cfg.Table))
+	}
+
+	if c.isTerminal() {
+		opts = append(opts, schema.WithProgressBar())
+	}
+
+	s, err := schema.New(db, opts...)
+	if err != nil {
+		return err
+	}
+
+	return f(&s)
+}
+
+func (c *cli) isTerminal() bool {
+	f, ok := c.stdout.(*os.File)
+
+	return ok && (isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd()))
+}
+
+func (c *cli) create() error {
+	if c.cfg.Path == "" || len(c.args) == 0 {
+		return fmt.Errorf("%w: path and description must be provided", ErrUsage)
+	}
+
+	fileName, err := sqlfile.Create(c.cfg.Path, strings.Join(c.args, " "), c.scheme)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(c.stdout, fileName)
+
+	return err
+}
+
+func (c *cli) upgrade(s *schema.Schema) error {
+	return s.UpgradeContext(c.ctx, c.cfg.Path, c.cfg.AppVersion)
+}
+
+func (c *cli) revert(s *schema.Schema) error {
+	n := 1
+
+	if len(c.args) > 0 {
+		var err error
+
+		n, err = strconv.Atoi(c.args[0])
+		if err != nil || n < 1 {
+			return fmt.Errorf("%w: number of scripts to revert must be greater than zero", ErrUsage)
+		}
+	}
+
+	return s.RevertNContext(c.ctx, c.cfg.Path, n)
+}
+
+func (c *cli) revertAll(s *schema.Schema) error {
+	return s.RevertNContext(c.ctx, c.cfg.Path, -1)
+}
+
+func (c *cli) recreate(s *schema.Schema) error {
+	return s.RecreateContext(c.ctx, c.cfg.Path, c.cfg.AppVersion)
+}
+
+func (c *cli) status(s *schema.Schema) error {
+	report, err := s.Status(c.cfg.Path)
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		return report.JSON(c.stdout)
+	}
+
+	return report.Table(c.stdout)
+}
+
+func (c *cli) validate(s *schema.Schema) error {
+	validation, err := s.Validate(c.cfg.Path)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range validation {
+		_, _ = fmt.Fprintf(c.stdout, "%s\t%s\n", p.Kind, p.Script)
+	}
+
+	return validation.Err()
+}
+
+func (c *cli) repair(s *schema.Schema) error {
+	report, err := s.Repair(c.cfg.Path)
+
+	for _, change := range report {
+		_, _ = fmt.Fprintf(c.stdout, "%s\t%s\n", change.Action, change.Script)
+	}
+
+	return err
+}
+
+// registered returns true if the database driver was registered, e.g. by importing it in the main package.
+func registered(driver string) bool {
+	for _, d := range sql.Drivers() {
+		if d == driver {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,220 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rebel-l/schema/cli"
+
+	_ "github.com/mattn/go-sqlite3" // sqlite3 driver is needed
+)
+
+func env(values map[string]string) func(string) string {
+	return func(key string) string {
+		return values[key]
+	}
+}
+
+func TestRun_Unhappy_Usage(t *testing.T) {
+	testCases := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "no command",
+			args: []string{},
+		},
+		{
+			name: "unknown command",
+			args: []string{"-driver", "sqlite3", "-dsn", "x.db", "-path", "./testdata/scripts", "downgrade"},
+		},
+		{
+			name: "unknown flag",
+			args: []string{"-unknown", "upgrade"},
+		},
+		{
+			name: "missing settings",
+			args: []string{"-driver", "sqlite3", "upgrade"},
+		},
+		{
+			name: "invalid number of scripts to revert",
+			args: []string{"-driver", "sqlite3", "-dsn", "x.db", "-path", "./testdata/scripts", "revert", "zero"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		args := testCase.args
+		t.Run(testCase.name, func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+
+			if code := cli.Run(args, env(nil), stdout, stderr); code != 2 {
+				t.Errorf("Expected exit code 2 but got %d", code)
+			}
+
+			if !strings.Contains(stderr.String(), "Usage: schema") {
+				t.Errorf("Expected usage to be printed but got %q", stderr.String())
+			}
+		})
+	}
+}
+
+func TestRun_Unhappy_UnregisteredDriver(t *testing.T) {
+	args := []string{"-driver", "postgres", "-dsn", "x", "-path", "./testdata/scripts", "upgrade"}
+	stderr := &bytes.Buffer{}
+
+	if code := cli.Run(args, env(nil), &bytes.Buffer{}, stderr); code != 2 {
+		t.Errorf("Expected exit code 2 but got %d", code)
+	}
+
+	if !strings.Contains(stderr.String(), "driver postgres is not registered (available: sqlite3)") ||
+		!strings.Contains(stderr.String(), "cli.Run") {
+		t.Errorf("Expected hint to register the driver but got %q", stderr.String())
+	}
+}
+
+func TestRun_Unhappy_ConfigFile(t *testing.T) {
+	stderr := &bytes.Buffer{}
+
+	code := cli.Run([]string{"-config", "./testdata/not_existing.json", "status"}, env(nil), &bytes.Buffer{}, stderr)
+	if code != 1 {
+		t.Errorf("Expected exit code 1 but got %d", code)
+	}
+
+	if !strings.Contains(stderr.String(), "failed to read config file") {
+		t.Errorf("Expected error message about config file but got %q", stderr.String())
+	}
+}
+
+func TestRun_Create(t *testing.T) {
+	dir := t.TempDir()
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	code := cli.Run([]string{"-path", dir, "create", "add", "users"}, env(nil), stdout, stderr)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0 but got %d: %s", code, stderr.String())
+	}
+
+	expected := filepath.Join(dir, "001_add_users.sql")
+	if strings.TrimSpace(stdout.String()) != expected {
+		t.Errorf("Expected file %s to be printed but got %q", expected, stdout.String())
+	}
+
+	if _, err := os.Stat(expected); err != nil {
+		t.Errorf("Expected file %s to be created: %s", expected, err)
+	}
+
+	if code = cli.Run([]string{"-path", dir, "create"}, env(nil), stdout, stderr); code != 2 {
+		t.Errorf("Expected exit code 2 without description but got %d", code)
+	}
+}
+
+func TestRun_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	dbFile := "./testdata/tmp/cli.db"
+	_ = os.Remove(dbFile)
+
+	// settings are taken from config file, environment and flags
+	getenv := env(map[string]string{
+		"SCHEMA_CONFIG": "./testdata/config.json",
+		"SCHEMA_PATH":   "./testdata/scripts",
+		"SCHEMA_TABLE":  "cli_script",
+	})
+	flags := []string{"-dsn", dbFile}
+
+	steps := []struct {
+		args     []string
+		applied  int
+		pending  int
+		exitCode int
+	}{
+		{args: []string{"upgrade"}, applied: 2},
+		{args: []string{"revert"}, applied: 1, pending: 1},
+		{args: []string{"upgrade", "-app-version", "2.0.0"}, applied: 2},
+		{args: []string{"revert-all"}, pending: 2},
+		{args: []string{"recreate"}, applied: 2},
+		{args: []string{"revert", "2"}, pending: 2},
+	}
+
+	for _, step := range steps {
+		stderr := &bytes.Buffer{}
+		if code := cli.Run(append(flags, step.args...), getenv, &bytes.Buffer{}, stderr); code != 0 {
+			t.Fatalf("%v: expected exit code 0 but got %d: %s", step.args, code, stderr.String())
+		}
+
+		stdout := &bytes.Buffer{}
+		if code := cli.Run(append(flags, "status", "-json"), getenv, stdout, stderr); code != 0 {
+			t.Fatalf("%v: expected exit code 0 on status but got %d: %s", step.args, code, stderr.String())
+		}
+
+		var report []map[string]string
+		if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+			t.Fatalf("%v: failed to parse status: %s", step.args, err)
+		}
+
+		applied, pending := 0, 0
+
+		for _, v := range report {
+			switch v["state"] {
+			case "applied":
+				applied++
+			case "pending":
+				pending++
+			}
+		}
+
+		if applied != step.applied || pending != step.pending {
+			t.Errorf("%v: expected %d applied and %d pending but got %v", step.args, step.applied, step.pending, report)
+		}
+	}
+
+	stdout := &bytes.Buffer{}
+	if code := cli.Run(append(flags, "validate"), getenv, stdout, &bytes.Buffer{}); code != 0 {
+		t.Errorf("Expected exit code 0 on validate but got %d", code)
+	}
+
+	if strings.Count(stdout.String(), "pending") != 2 {
+		t.Errorf("Expected 2 pending scripts but got %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := cli.Run(append(flags, "repair"), getenv, stdout, &bytes.Buffer{}); code != 0 {
+		t.Errorf("Expected exit code 0 on repair but got %d", code)
+	}
+
+	if stdout.Len() != 0 {
+		t.Errorf("Expected nothing to repair but got %q", stdout.String())
+	}
+}
+
+func TestRunContext_Canceled(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	dbFile := "./testdata/tmp/cli_canceled.db"
+	_ = os.Remove(dbFile)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	args := []string{"-driver", "sqlite3", "-dsn", dbFile, "-path", "./testdata/scripts", "upgrade"}
+	stderr := &bytes.Buffer{}
+
+	if code := cli.RunContext(ctx, args, env(nil), &bytes.Buffer{}, stderr); code != 1 {
+		t.Errorf("Expected exit code 1 but got %d", code)
+	}
+
+	if !strings.Contains(stderr.String(), context.Canceled.Error()) {
+		t.Errorf("Expected the upgrade to be cancelled but got %q", stderr.String())
+	}
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+const (
+	envDriver     = "SCHEMA_DRIVER"
+	envDSN        = "SCHEMA_DSN"
+	envPath       = "SCHEMA_PATH"
+	envAppVersion = "SCHEMA_APP_VERSION"
+	envConfig     = "SCHEMA_CONFIG"
+	envTable      = "SCHEMA_TABLE"
+	envNamespace  = "SCHEMA_NAMESPACE"
+)
+
+// Config contains the settings to connect to the database and to find the scripts.
+type Config struct {
+	Driver     string `json:"driver"`
+	DSN        string `json:"dsn"`
+	Path       string `json:"path"`
+	AppVersion string `json:"app_version"`
+	Table      string `json:"table"`
+	Namespace  string `json:"namespace"`
+}
+
+// loadConfig merges the settings of the config file, the environment variables and the flags. Flags take precedence
+// over environment variables which take precedence over the config file.
+func loadConfig(fs *flag.FlagSet, flags *Config, configFile string, getenv func(string) string) (*Config, error) {
+	cfg := &Config{}
+
+	if configFile == "" {
+		configFile = getenv(envConfig)
+	}
+
+	if configFile != "" {
+		content, err := os.ReadFile(configFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		if err = json.Unmarshal(content, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", configFile, err)
+		}
+	}
+
+	for env, value := range map[string]*string{
+		envDriver:     &cfg.Driver,
+		envDSN:        &cfg.DSN,
+		envPath:       &cfg.Path,
+		envAppVersion: &cfg.AppVersion,
+		envTable:      &cfg.Table,
+		envNamespace:  &cfg.Namespace,
+	} {
+		if v := getenv(env); v != "" {
+			*value = v
+		}
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "driver":
+			cfg.Driver = flags.Driver
+		case "dsn":
+			cfg.DSN = flags.DSN
+		case "path":
+			cfg.Path = flags.Path
+		case "app-version":
+			cfg.AppVersion = flags.AppVersion
+		case "table":
+			cfg.Table = flags.Table
+		case "namespace":
+			cfg.Namespace = flags.Namespace
+		}
+	})
+
+	return cfg, nil
+}
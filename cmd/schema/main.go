@@ -0,0 +1,19 @@
+// Command schema applies, reverts and reports the SQL scripts of a folder, see package cli for the usage.
+// It includes the drivers of all supported dialects: sqlite3, postgres, mysql and sqlserver. For other drivers build
+// your own main calling cli.Run and importing the driver.
+package main
+
+import (
+	"os"
+
+	"github.com/rebel-l/schema/cli"
+
+	_ "github.com/go-sql-driver/mysql"  // mysql driver
+	_ "github.com/lib/pq"               // postgres driver
+	_ "github.com/mattn/go-sqlite3"     // sqlite3 driver
+	_ "github.com/microsoft/go-mssqldb" // sqlserver driver
+)
+
+func main() {
+	os.Exit(cli.Run(os.Args[1:], os.Getenv, os.Stdout, os.Stderr))
+}
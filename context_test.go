@@ -0,0 +1,199 @@
+package schema_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/rebel-l/schema"
+	"github.com/rebel-l/schema/initdb"
+	"github.com/rebel-l/schema/mocks/schema_mock"
+	"github.com/rebel-l/schema/store"
+	"github.com/rebel-l/schema/utils/testdb"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestSchema_UpgradeContext_Canceled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+
+	mockApplier := schema_mock.NewMockApplier(ctrl)
+	mockApplier.EXPECT().ApplyScriptFSContext(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	mockScripter := schema_mock.NewMockScripter(ctrl)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(store.SchemaScriptCollection{}, nil)
+	mockScripter.EXPECT().AddContext(gomock.Any(), gomock.Any()).Times(0)
+
+	s := newSchema(t, mockDB, schema.WithApplier(mockApplier), schema.WithScripter(mockScripter))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := s.UpgradeContext(ctx, "./testdata/unit", ""); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected error %s but got %v", context.Canceled, err)
+	}
+}
+
+type ctxKey struct{}
+
+func TestSchema_UpgradeContext_PassesContextToScripter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+
+	mockApplier := schema_mock.NewMockApplier(ctrl)
+	mockApplier.EXPECT().
+		ApplyScriptFSContext(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(2).
+		DoAndReturn(runCallbacks(mockDB))
+
+	checkContext := func(ctx context.Context) {
+		if ctx.Value(ctxKey{}) != "command" {
+			t.Error("Expected the context of the command to be passed to the scripter")
+		}
+	}
+
+	mockScripter := schema_mock.NewMockScripter(ctrl)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(store.SchemaScriptCollection{}, nil)
+	mockScripter.EXPECT().
+		AddWithContext(gomock.Any(), mockDB, gomock.Any()).
+		Times(2).
+		DoAndReturn(func(ctx context.Context, _ interface{}, _ *store.SchemaScript) error {
+			checkContext(ctx)
+			return nil
+		})
+
+	s := newSchema(t, mockDB, schema.WithApplier(mockApplier), schema.WithScripter(mockScripter))
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "command")
+	if err := s.UpgradeContext(ctx, "./testdata/unit", ""); err != nil {
+		t.Errorf("Expected no error but got %s", err)
+	}
+}
+
+func TestSchema_WithScriptTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+
+	mockApplier := schema_mock.NewMockApplier(ctrl)
+	mockApplier.EXPECT().
+		ApplyScriptFSContext(gomock.Any(), gomock.Any(), "001.sql", gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ fs.FS, _ string, _ ...initdb.Callback) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+	var logged *store.SchemaScript
+
+	mockScripter := schema_mock.NewMockScripter(ctrl)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(store.SchemaScriptCollection{}, nil)
+	mockScripter.EXPECT().
+		AddContext(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, entry *store.SchemaScript) error {
+			// the failure needs to be logged although the script timed out
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			logged = entry
+
+			return nil
+		})
+
+	s := newSchema(t, mockDB,
+		schema.WithScriptTimeout(10*time.Millisecond),
+		schema.WithApplier(mockApplier),
+		schema.WithScripter(mockScripter),
+	)
+
+	if err := s.Upgrade("./testdata/unit", ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected error %s but got %v", context.DeadlineExceeded, err)
+	}
+
+	if logged == nil || logged.Status != store.StatusTimeout {
+		t.Errorf("Expected script to be logged with status %s but got %#v", store.StatusTimeout, logged)
+	}
+}
+
+func TestSchema_WithTimeout_LockWait(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Exec(gomock.Any()).Return(nil, nil)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+
+	mockLocker := schema_mock.NewMockLocker(ctrl)
+	mockLocker.EXPECT().AcquireContext(gomock.Any(), gomock.Any(), gomock.Any()).MinTimes(1).Return(store.ErrLocked)
+	mockLocker.EXPECT().ReleaseContext(gomock.Any(), gomock.Any()).Times(0)
+
+	s := newSchema(t, mockDB,
+		schema.WithLock(time.Hour),
+		schema.WithTimeout(50*time.Millisecond),
+		schema.WithLocker(mockLocker),
+	)
+
+	start := time.Now()
+
+	err := s.Upgrade("./testdata/unit", "")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected error %s but got %v", context.DeadlineExceeded, err)
+	}
+
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("Expected to stop waiting for the lock after the timeout")
+	}
+}
+
+func TestSchema_WithScriptTimeout_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_script_timeout.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	s := newSchema(t, db, schema.WithScriptTimeout(100*time.Millisecond))
+
+	if err = s.Upgrade("./testdata/timeout", ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected error %s but got %v", context.DeadlineExceeded, err)
+	}
+
+	data, err := store.NewSchemaScriptMapper(db).GetAll()
+	if err != nil {
+		t.Fatalf("not able get rows from table: %s", err)
+	}
+
+	if len(data) != 1 || data[0].Status != store.StatusTimeout {
+		t.Errorf("Expected one entry with status %s but got %#v", store.StatusTimeout, data)
+	}
+
+	var counter []uint32
+	if err = db.Select(&counter, "SELECT count(*) FROM slow;"); err == nil {
+		t.Error("Expected that the script was rolled back")
+	}
+
+	report, err := s.Status("./testdata/timeout")
+	if err != nil {
+		t.Fatalf("Expected no error on status but got %s", err)
+	}
+
+	if report.Count(schema.StateTimeout) != 1 {
+		t.Errorf("Expected one script with state %s", schema.StateTimeout)
+	}
+}
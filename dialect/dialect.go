@@ -0,0 +1,120 @@
+// Package dialect provides the SQL statements which differ between the supported databases
+package dialect
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	// ErrUnknownDialect is used if no dialect is registered for a name
+	ErrUnknownDialect = errors.New("unknown dialect")
+
+	dialects = map[string]Dialect{
+		"sqlite":     SQLite{},
+		"sqlite3":    SQLite{},
+		"postgres":   Postgres{},
+		"postgresql": Postgres{},
+		"pgx":        Postgres{},
+		"mysql":      MySQL{},
+		"sqlserver":  SQLServer{},
+		"mssql":      SQLServer{},
+	}
+)
+
+// Dialect provides the SQL statements used to manage the tables of this package.
+type Dialect interface {
+	// Name returns the name of the database.
+	Name() string
+
+	// Rebind replaces the placeholders "?" of the query by the placeholders of the database.
+	Rebind(query string) string
+
+	// CreateScriptTable returns the DDL of the table logging the script executions.
+	CreateScriptTable(table string) string
+
+	// CreateLockTable returns the DDL of the table holding the migration lock.
+	CreateLockTable(table string) string
+
+	// CreateMetaTable returns the DDL of the table holding the meta-version of the tables of this package.
+	CreateMetaTable(table string) string
+
+	// AddColumn returns the statement to add a column to an existing table. The definition contains the type and
+	// the constraints of the column, e.g. "BIGINT NOT NULL DEFAULT 0".
+	AddColumn(table string, column string, definition string) string
+
+	// DropTable returns the statement to drop the table if it exists.
+	DropTable(table string) string
+
+	// TableExists returns a query counting the tables with the given name in the namespace. An empty namespace stands
+	// for the default one of the connection. Both names are passed unquoted.
+	TableExists(namespace string, table string) string
+
+	// Quote returns the identifier quoted for the database, e.g. "schema_script" or `schema_script`.
+	Quote(identifier string) string
+
+	// AdvisoryLock returns the query trying to take the advisory lock with the given name without waiting and the
+	// statement releasing it. The query returns 1 if the lock was taken, otherwise 0. The lock belongs to the session,
+	// so both need to run on the same connection. Empty statements mean the database has no advisory locks.
+	AdvisoryLock(name string) (lock string, unlock string)
+
+	// BackslashEscapes returns true if a backslash escapes characters inside of string literals, e.g. 'It\'s'.
+	BackslashEscapes() bool
+
+	// Insert returns the statement to insert a row with the given columns. If the returned flag is true, the
+	// statement returns the id of the new row as result set, otherwise it needs to be fetched by LastInsertId().
+	Insert(table string, columns ...string) (string, bool)
+}
+
+// ByName returns the dialect for the name of a database or driver, e.g. "postgres" or "sqlite3".
+func ByName(name string) (Dialect, error) {
+	d, ok := dialects[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDialect, name)
+	}
+
+	return d, nil
+}
+
+// Detect returns the dialect matching the driver of the database connection, e.g. of a *sqlx.DB. It falls back to
+// SQLite if the driver is unknown or the connection doesn't provide its driver name.
+func Detect(db interface{}) Dialect {
+	if named, ok := db.(interface{ DriverName() string }); ok {
+		if d, err := ByName(named.DriverName()); err == nil {
+			return d
+		}
+	}
+
+	return SQLite{}
+}
+
+// QualifiedName returns the quoted name of the table prefixed by the quoted namespace, e.g. "billing"."schema_script".
+// Without namespace only the quoted name of the table is returned.
+func QualifiedName(d Dialect, namespace string, table string) string {
+	if namespace == "" {
+		return d.Quote(table)
+	}
+
+	return d.Quote(namespace) + "." + d.Quote(table)
+}
+
+func quote(identifier string, left string, right string) string {
+	return left + strings.ReplaceAll(identifier, right, right+right) + right
+}
+
+func literal(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func insert(bindType int, table string, columns []string, output string, returning string) string {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	q := fmt.Sprintf(
+		"INSERT INTO %s (%s)%s VALUES (%s)%s;",
+		table, strings.Join(columns, ", "), output, placeholders, returning,
+	)
+
+	return sqlx.Rebind(bindType, q)
+}
@@ -0,0 +1,160 @@
+package dialect_test
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rebel-l/schema/dialect"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestDialect_Golden(t *testing.T) {
+	testCases := []dialect.Dialect{
+		dialect.SQLite{},
+		dialect.Postgres{},
+		dialect.MySQL{},
+		dialect.SQLServer{},
+	}
+
+	for _, d := range testCases {
+		d := d
+		t.Run(d.Name(), func(t *testing.T) {
+			script := dialect.QualifiedName(d, "", "schema_script")
+			lock := dialect.QualifiedName(d, "", "schema_lock")
+			insert, returnsID := d.Insert(script, "script_name", "executed_at")
+			advisoryLock, advisoryUnlock := d.AdvisoryLock("billing.schema_lock")
+
+			statements := []string{
+				"-- create script table\n" + d.CreateScriptTable(script),
+				"-- create lock table\n" + d.CreateLockTable(lock),
+				"-- create meta table\n" + d.CreateMetaTable(dialect.QualifiedName(d, "", "schema_meta")),
+				"-- add column\n" + d.AddColumn(script, "checksum", "VARCHAR(64) NOT NULL DEFAULT ''"),
+				"-- drop table\n" + d.DropTable(script),
+				"-- table exists\n" + d.TableExists("", "schema_script"),
+				"-- table exists in namespace\n" + d.TableExists("billing", "schema_script"),
+				"-- qualified name\n" + dialect.QualifiedName(d, "billing", "schema_script"),
+				"-- rebind\n" + d.Rebind("DELETE FROM "+lock+" WHERE id = ? AND owner = ?;"),
+				"-- insert (returns id: " + boolString(returnsID) + ")\n" + insert,
+				"-- backslash escapes: " + boolString(d.BackslashEscapes()),
+				"-- advisory lock\n" + advisoryLock,
+				"-- advisory unlock\n" + advisoryUnlock,
+			}
+
+			actual := strings.Join(statements, "\n\n") + "\n"
+			golden := filepath.Join("testdata", d.Name()+".golden.sql")
+
+			if *update {
+				if err := os.WriteFile(golden, []byte(actual), 0600); err != nil {
+					t.Fatalf("failed to update golden file: %s", err)
+				}
+			}
+
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %s", err)
+			}
+
+			if string(expected) != actual {
+				t.Errorf("Expected SQL\n%s\nbut got\n%s", expected, actual)
+			}
+		})
+	}
+}
+
+func boolString(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
+
+func TestByName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{name: "sqlite3", expected: "sqlite"},
+		{name: "Postgres", expected: "postgres"},
+		{name: "pgx", expected: "postgres"},
+		{name: "mysql", expected: "mysql"},
+		{name: "mssql", expected: "sqlserver"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			d, err := dialect.ByName(testCase.name)
+			if err != nil {
+				t.Fatalf("Expected no error but got %s", err)
+			}
+
+			if d.Name() != testCase.expected {
+				t.Errorf("Expected dialect %s but got %s", testCase.expected, d.Name())
+			}
+		})
+	}
+
+	if _, err := dialect.ByName("oracle"); !errors.Is(err, dialect.ErrUnknownDialect) {
+		t.Errorf("Expected error %s but got %v", dialect.ErrUnknownDialect, err)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name     string
+		db       interface{}
+		expected string
+	}{
+		{name: "sqlx postgres", db: sqlx.NewDb(nil, "postgres"), expected: "postgres"},
+		{name: "sqlx mysql", db: sqlx.NewDb(nil, "mysql"), expected: "mysql"},
+		{name: "unknown driver", db: sqlx.NewDb(nil, "unknown"), expected: "sqlite"},
+		{name: "without driver name", db: struct{}{}, expected: "sqlite"},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			if actual := dialect.Detect(testCase.db).Name(); actual != testCase.expected {
+				t.Errorf("Expected dialect %s but got %s", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestQualifiedName_Escaping(t *testing.T) {
+	testCases := []struct {
+		dialect  dialect.Dialect
+		expected string
+	}{
+		{dialect: dialect.SQLite{}, expected: `"my""ns"."my""table"`},
+		{dialect: dialect.Postgres{}, expected: `"my""ns"."my""table"`},
+		{dialect: dialect.MySQL{}, expected: "`my\"ns`.`my\"table`"},
+		{dialect: dialect.SQLServer{}, expected: `[my"ns].[my"table]`},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.dialect.Name(), func(t *testing.T) {
+			actual := dialect.QualifiedName(testCase.dialect, `my"ns`, `my"table`)
+			if actual != testCase.expected {
+				t.Errorf("Expected name %s but got %s", testCase.expected, actual)
+			}
+		})
+	}
+
+	if actual := (dialect.SQLServer{}).Quote("a]b"); actual != "[a]]b]" {
+		t.Errorf("Expected name [a]]b] but got %s", actual)
+	}
+
+	if actual := (dialect.MySQL{}).Quote("a`b"); actual != "`a``b`" {
+		t.Errorf("Expected name `a``b` but got %s", actual)
+	}
+}
@@ -0,0 +1,97 @@
+package dialect
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// MySQL provides the statements for MySQL and MariaDB.
+type MySQL struct{}
+
+// Name returns the name of the database.
+func (MySQL) Name() string {
+	return "mysql"
+}
+
+// Rebind returns the query unchanged as MySQL uses "?" as placeholder.
+func (MySQL) Rebind(query string) string {
+	return sqlx.Rebind(sqlx.QUESTION, query)
+}
+
+// CreateScriptTable returns the DDL of the table logging the script executions.
+func (MySQL) CreateScriptTable(table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+  id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  script_name VARCHAR(255) NOT NULL,
+  executed_at DATETIME NOT NULL,
+  execution_status VARCHAR(100) NOT NULL,
+  app_version VARCHAR(30) NULL,
+  error_msg TEXT NULL,
+  checksum VARCHAR(64) NOT NULL DEFAULT '',
+  duration_ms BIGINT NOT NULL DEFAULT 0,
+  executed_by VARCHAR(255) NOT NULL DEFAULT ''
+);`, table)
+}
+
+// CreateLockTable returns the DDL of the table holding the migration lock.
+func (MySQL) CreateLockTable(table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+  id INTEGER NOT NULL PRIMARY KEY,
+  owner VARCHAR(255) NOT NULL,
+  acquired_at DATETIME NOT NULL,
+  expires_at DATETIME NOT NULL
+);`, table)
+}
+
+// CreateMetaTable returns the DDL of the table holding the meta-version of the tables of this package.
+func (MySQL) CreateMetaTable(table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+  id INTEGER NOT NULL PRIMARY KEY,
+  version INTEGER NOT NULL
+);`, table)
+}
+
+// AddColumn returns the statement to add a column to an existing table.
+func (MySQL) AddColumn(table string, column string, definition string) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", table, column, definition)
+}
+
+// DropTable returns the statement to drop the table if it exists.
+func (MySQL) DropTable(table string) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s;", table)
+}
+
+// TableExists returns a query counting the tables with the given name in the namespace or the current database.
+func (MySQL) TableExists(namespace string, table string) string {
+	schema := "DATABASE()"
+	if namespace != "" {
+		schema = literal(namespace)
+	}
+
+	return fmt.Sprintf(
+		"SELECT count(*) FROM information_schema.tables WHERE table_schema = %s AND table_name = %s;",
+		schema, literal(table),
+	)
+}
+
+// Insert returns the statement to insert a row, the id is fetched by LastInsertId().
+func (MySQL) Insert(table string, columns ...string) (string, bool) {
+	return insert(sqlx.QUESTION, table, columns, "", ""), false
+}
+
+// Quote returns the identifier in backticks.
+func (MySQL) Quote(identifier string) string {
+	return quote(identifier, "`", "`")
+}
+
+// AdvisoryLock returns the statements of GET_LOCK() without timeout and RELEASE_LOCK().
+func (MySQL) AdvisoryLock(name string) (string, string) {
+	return fmt.Sprintf("SELECT COALESCE(GET_LOCK(%s, 0), 0);", literal(name)),
+		fmt.Sprintf("SELECT RELEASE_LOCK(%s);", literal(name))
+}
+
+// BackslashEscapes returns true as MySQL escapes by backslash unless the mode NO_BACKSLASH_ESCAPES is set.
+func (MySQL) BackslashEscapes() bool {
+	return true
+}
@@ -0,0 +1,100 @@
+package dialect
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Postgres provides the statements for PostgreSQL.
+type Postgres struct{}
+
+// Name returns the name of the database.
+func (Postgres) Name() string {
+	return "postgres"
+}
+
+// Rebind replaces the placeholders "?" by "$1", "$2", ...
+func (Postgres) Rebind(query string) string {
+	return sqlx.Rebind(sqlx.DOLLAR, query)
+}
+
+// CreateScriptTable returns the DDL of the table logging the script executions.
+func (Postgres) CreateScriptTable(table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+  id BIGSERIAL NOT NULL PRIMARY KEY,
+  script_name TEXT NOT NULL,
+  executed_at TIMESTAMP NOT NULL,
+  execution_status VARCHAR(100) NOT NULL,
+  app_version VARCHAR(30) NULL,
+  error_msg TEXT NULL,
+  checksum VARCHAR(64) NOT NULL DEFAULT '',
+  duration_ms BIGINT NOT NULL DEFAULT 0,
+  executed_by VARCHAR(255) NOT NULL DEFAULT ''
+);`, table)
+}
+
+// CreateLockTable returns the DDL of the table holding the migration lock.
+func (Postgres) CreateLockTable(table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+  id INTEGER NOT NULL PRIMARY KEY,
+  owner TEXT NOT NULL,
+  acquired_at TIMESTAMP NOT NULL,
+  expires_at TIMESTAMP NOT NULL
+);`, table)
+}
+
+// CreateMetaTable returns the DDL of the table holding the meta-version of the tables of this package.
+func (Postgres) CreateMetaTable(table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+  id INTEGER NOT NULL PRIMARY KEY,
+  version INTEGER NOT NULL
+);`, table)
+}
+
+// AddColumn returns the statement to add a column to an existing table.
+func (Postgres) AddColumn(table string, column string, definition string) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", table, column, definition)
+}
+
+// DropTable returns the statement to drop the table if it exists.
+func (Postgres) DropTable(table string) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s;", table)
+}
+
+// TableExists returns a query counting the tables with the given name in the namespace or the current schema.
+func (Postgres) TableExists(namespace string, table string) string {
+	schema := "current_schema()"
+	if namespace != "" {
+		schema = literal(namespace)
+	}
+
+	return fmt.Sprintf(
+		"SELECT count(*) FROM information_schema.tables WHERE table_schema = %s AND table_name = %s;",
+		schema, literal(table),
+	)
+}
+
+// Insert returns the statement to insert a row which returns the id by "RETURNING id".
+func (Postgres) Insert(table string, columns ...string) (string, bool) {
+	return insert(sqlx.DOLLAR, table, columns, "", " RETURNING id"), true
+}
+
+// Quote returns the identifier in double quotes.
+func (Postgres) Quote(identifier string) string {
+	return quote(identifier, `"`, `"`)
+}
+
+// AdvisoryLock returns the statements of pg_try_advisory_lock() and pg_advisory_unlock() with the hash of the name as
+// key.
+func (Postgres) AdvisoryLock(name string) (string, string) {
+	key := fmt.Sprintf("hashtext(%s)", literal(name))
+
+	return fmt.Sprintf("SELECT CASE WHEN pg_try_advisory_lock(%s) THEN 1 ELSE 0 END;", key),
+		fmt.Sprintf("SELECT pg_advisory_unlock(%s);", key)
+}
+
+// BackslashEscapes returns false as PostgreSQL only supports them in strings prefixed by E like E'It\'s'.
+func (Postgres) BackslashEscapes() bool {
+	return false
+}
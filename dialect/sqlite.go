@@ -0,0 +1,94 @@
+package dialect
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SQLite provides the statements for SQLite. It is the default dialect.
+type SQLite struct{}
+
+// Name returns the name of the database.
+func (SQLite) Name() string {
+	return "sqlite"
+}
+
+// Rebind returns the query unchanged as SQLite uses "?" as placeholder.
+func (SQLite) Rebind(query string) string {
+	return sqlx.Rebind(sqlx.QUESTION, query)
+}
+
+// CreateScriptTable returns the DDL of the table logging the script executions.
+func (SQLite) CreateScriptTable(table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  script_name TEXT NOT NULL,
+  executed_at DATETIME NOT NULL,
+  execution_status VARCHAR(100) NOT NULL,
+  app_version CHAR(30) NULL,
+  error_msg TEXT NULL,
+  checksum CHAR(64) NOT NULL DEFAULT '',
+  duration_ms INTEGER NOT NULL DEFAULT 0,
+  executed_by TEXT NOT NULL DEFAULT ''
+);`, table)
+}
+
+// CreateLockTable returns the DDL of the table holding the migration lock.
+func (SQLite) CreateLockTable(table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+  id INTEGER NOT NULL PRIMARY KEY,
+  owner TEXT NOT NULL,
+  acquired_at DATETIME NOT NULL,
+  expires_at DATETIME NOT NULL
+);`, table)
+}
+
+// CreateMetaTable returns the DDL of the table holding the meta-version of the tables of this package.
+func (SQLite) CreateMetaTable(table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+  id INTEGER NOT NULL PRIMARY KEY,
+  version INTEGER NOT NULL
+);`, table)
+}
+
+// AddColumn returns the statement to add a column to an existing table.
+func (SQLite) AddColumn(table string, column string, definition string) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", table, column, definition)
+}
+
+// DropTable returns the statement to drop the table if it exists.
+func (SQLite) DropTable(table string) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s;", table)
+}
+
+// TableExists returns a query counting the tables with the given name. The namespace is the name of an attached
+// database.
+func (SQLite) TableExists(namespace string, table string) string {
+	master := "sqlite_master"
+	if namespace != "" {
+		master = quote(namespace, `"`, `"`) + "." + master
+	}
+
+	return fmt.Sprintf("SELECT count(*) FROM %s WHERE type = 'table' AND name = %s;", master, literal(table))
+}
+
+// Insert returns the statement to insert a row, the id is fetched by LastInsertId().
+func (SQLite) Insert(table string, columns ...string) (string, bool) {
+	return insert(sqlx.QUESTION, table, columns, "", ""), false
+}
+
+// Quote returns the identifier in double quotes.
+func (SQLite) Quote(identifier string) string {
+	return quote(identifier, `"`, `"`)
+}
+
+// AdvisoryLock returns empty statements as SQLite has no advisory locks.
+func (SQLite) AdvisoryLock(_ string) (string, string) {
+	return "", ""
+}
+
+// BackslashEscapes returns false as SQLite only escapes quotes by doubling them.
+func (SQLite) BackslashEscapes() bool {
+	return false
+}
@@ -0,0 +1,105 @@
+package dialect
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SQLServer provides the statements for Microsoft SQL Server 2016 or higher.
+type SQLServer struct{}
+
+// Name returns the name of the database.
+func (SQLServer) Name() string {
+	return "sqlserver"
+}
+
+// Rebind replaces the placeholders "?" by "@p1", "@p2", ...
+func (SQLServer) Rebind(query string) string {
+	return sqlx.Rebind(sqlx.AT, query)
+}
+
+// CreateScriptTable returns the DDL of the table logging the script executions.
+func (SQLServer) CreateScriptTable(table string) string {
+	return fmt.Sprintf(`IF OBJECT_ID(N'%s', N'U') IS NULL
+CREATE TABLE %s (
+  id BIGINT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+  script_name NVARCHAR(255) NOT NULL,
+  executed_at DATETIME2 NOT NULL,
+  execution_status NVARCHAR(100) NOT NULL,
+  app_version NVARCHAR(30) NULL,
+  error_msg NVARCHAR(MAX) NULL,
+  checksum NVARCHAR(64) NOT NULL DEFAULT '',
+  duration_ms BIGINT NOT NULL DEFAULT 0,
+  executed_by NVARCHAR(255) NOT NULL DEFAULT ''
+);`, table, table)
+}
+
+// CreateLockTable returns the DDL of the table holding the migration lock.
+func (SQLServer) CreateLockTable(table string) string {
+	return fmt.Sprintf(`IF OBJECT_ID(N'%s', N'U') IS NULL
+CREATE TABLE %s (
+  id INTEGER NOT NULL PRIMARY KEY,
+  owner NVARCHAR(255) NOT NULL,
+  acquired_at DATETIME2 NOT NULL,
+  expires_at DATETIME2 NOT NULL
+);`, table, table)
+}
+
+// CreateMetaTable returns the DDL of the table holding the meta-version of the tables of this package.
+func (SQLServer) CreateMetaTable(table string) string {
+	return fmt.Sprintf(`IF OBJECT_ID(N'%s', N'U') IS NULL
+CREATE TABLE %s (
+  id INTEGER NOT NULL PRIMARY KEY,
+  version INTEGER NOT NULL
+);`, table, table)
+}
+
+// AddColumn returns the statement to add a column to an existing table.
+func (SQLServer) AddColumn(table string, column string, definition string) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD %s %s;", table, column, definition)
+}
+
+// DropTable returns the statement to drop the table if it exists.
+func (SQLServer) DropTable(table string) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s;", table)
+}
+
+// TableExists returns a query counting the tables with the given name in the namespace or the default schema.
+func (SQLServer) TableExists(namespace string, table string) string {
+	schema := "SCHEMA_NAME()"
+	if namespace != "" {
+		schema = literal(namespace)
+	}
+
+	return fmt.Sprintf(
+		"SELECT count(*) FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = %s AND TABLE_NAME = %s;",
+		schema, literal(table),
+	)
+}
+
+// Insert returns the statement to insert a row which returns the id by "OUTPUT INSERTED.id".
+func (SQLServer) Insert(table string, columns ...string) (string, bool) {
+	return insert(sqlx.AT, table, columns, " OUTPUT INSERTED.id", ""), true
+}
+
+// Quote returns the identifier in square brackets.
+func (SQLServer) Quote(identifier string) string {
+	return quote(identifier, "[", "]")
+}
+
+// AdvisoryLock returns the statements of sp_getapplock without timeout and sp_releaseapplock, both owned by the
+// session.
+func (SQLServer) AdvisoryLock(name string) (string, string) {
+	resource := "N" + literal(name)
+
+	return fmt.Sprintf(`DECLARE @result INT;
+EXEC @result = sp_getapplock @Resource = %s, @LockMode = 'Exclusive', @LockOwner = 'Session', @LockTimeout = 0;
+SELECT CASE WHEN @result >= 0 THEN 1 ELSE 0 END;`, resource),
+		fmt.Sprintf("EXEC sp_releaseapplock @Resource = %s, @LockOwner = 'Session';", resource)
+}
+
+// BackslashEscapes returns false as SQL Server only escapes quotes by doubling them.
+func (SQLServer) BackslashEscapes() bool {
+	return false
+}
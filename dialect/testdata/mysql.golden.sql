@@ -0,0 +1,55 @@
+-- create script table
+CREATE TABLE IF NOT EXISTS `schema_script` (
+  id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  script_name VARCHAR(255) NOT NULL,
+  executed_at DATETIME NOT NULL,
+  execution_status VARCHAR(100) NOT NULL,
+  app_version VARCHAR(30) NULL,
+  error_msg TEXT NULL,
+  checksum VARCHAR(64) NOT NULL DEFAULT '',
+  duration_ms BIGINT NOT NULL DEFAULT 0,
+  executed_by VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+-- create lock table
+CREATE TABLE IF NOT EXISTS `schema_lock` (
+  id INTEGER NOT NULL PRIMARY KEY,
+  owner VARCHAR(255) NOT NULL,
+  acquired_at DATETIME NOT NULL,
+  expires_at DATETIME NOT NULL
+);
+
+-- create meta table
+CREATE TABLE IF NOT EXISTS `schema_meta` (
+  id INTEGER NOT NULL PRIMARY KEY,
+  version INTEGER NOT NULL
+);
+
+-- add column
+ALTER TABLE `schema_script` ADD COLUMN checksum VARCHAR(64) NOT NULL DEFAULT '';
+
+-- drop table
+DROP TABLE IF EXISTS `schema_script`;
+
+-- table exists
+SELECT count(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = 'schema_script';
+
+-- table exists in namespace
+SELECT count(*) FROM information_schema.tables WHERE table_schema = 'billing' AND table_name = 'schema_script';
+
+-- qualified name
+`billing`.`schema_script`
+
+-- rebind
+DELETE FROM `schema_lock` WHERE id = ? AND owner = ?;
+
+-- insert (returns id: no)
+INSERT INTO `schema_script` (script_name, executed_at) VALUES (?, ?);
+
+-- backslash escapes: yes
+
+-- advisory lock
+SELECT COALESCE(GET_LOCK('billing.schema_lock', 0), 0);
+
+-- advisory unlock
+SELECT RELEASE_LOCK('billing.schema_lock');
@@ -0,0 +1,55 @@
+-- create script table
+CREATE TABLE IF NOT EXISTS "schema_script" (
+  id BIGSERIAL NOT NULL PRIMARY KEY,
+  script_name TEXT NOT NULL,
+  executed_at TIMESTAMP NOT NULL,
+  execution_status VARCHAR(100) NOT NULL,
+  app_version VARCHAR(30) NULL,
+  error_msg TEXT NULL,
+  checksum VARCHAR(64) NOT NULL DEFAULT '',
+  duration_ms BIGINT NOT NULL DEFAULT 0,
+  executed_by VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+-- create lock table
+CREATE TABLE IF NOT EXISTS "schema_lock" (
+  id INTEGER NOT NULL PRIMARY KEY,
+  owner TEXT NOT NULL,
+  acquired_at TIMESTAMP NOT NULL,
+  expires_at TIMESTAMP NOT NULL
+);
+
+-- create meta table
+CREATE TABLE IF NOT EXISTS "schema_meta" (
+  id INTEGER NOT NULL PRIMARY KEY,
+  version INTEGER NOT NULL
+);
+
+-- add column
+ALTER TABLE "schema_script" ADD COLUMN checksum VARCHAR(64) NOT NULL DEFAULT '';
+
+-- drop table
+DROP TABLE IF EXISTS "schema_script";
+
+-- table exists
+SELECT count(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = 'schema_script';
+
+-- table exists in namespace
+SELECT count(*) FROM information_schema.tables WHERE table_schema = 'billing' AND table_name = 'schema_script';
+
+-- qualified name
+"billing"."schema_script"
+
+-- rebind
+DELETE FROM "schema_lock" WHERE id = $1 AND owner = $2;
+
+-- insert (returns id: yes)
+INSERT INTO "schema_script" (script_name, executed_at) VALUES ($1, $2) RETURNING id;
+
+-- backslash escapes: no
+
+-- advisory lock
+SELECT CASE WHEN pg_try_advisory_lock(hashtext('billing.schema_lock')) THEN 1 ELSE 0 END;
+
+-- advisory unlock
+SELECT pg_advisory_unlock(hashtext('billing.schema_lock'));
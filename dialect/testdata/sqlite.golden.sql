@@ -0,0 +1,55 @@
+-- create script table
+CREATE TABLE IF NOT EXISTS "schema_script" (
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  script_name TEXT NOT NULL,
+  executed_at DATETIME NOT NULL,
+  execution_status VARCHAR(100) NOT NULL,
+  app_version CHAR(30) NULL,
+  error_msg TEXT NULL,
+  checksum CHAR(64) NOT NULL DEFAULT '',
+  duration_ms INTEGER NOT NULL DEFAULT 0,
+  executed_by TEXT NOT NULL DEFAULT ''
+);
+
+-- create lock table
+CREATE TABLE IF NOT EXISTS "schema_lock" (
+  id INTEGER NOT NULL PRIMARY KEY,
+  owner TEXT NOT NULL,
+  acquired_at DATETIME NOT NULL,
+  expires_at DATETIME NOT NULL
+);
+
+-- create meta table
+CREATE TABLE IF NOT EXISTS "schema_meta" (
+  id INTEGER NOT NULL PRIMARY KEY,
+  version INTEGER NOT NULL
+);
+
+-- add column
+ALTER TABLE "schema_script" ADD COLUMN checksum VARCHAR(64) NOT NULL DEFAULT '';
+
+-- drop table
+DROP TABLE IF EXISTS "schema_script";
+
+-- table exists
+SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_script';
+
+-- table exists in namespace
+SELECT count(*) FROM "billing".sqlite_master WHERE type = 'table' AND name = 'schema_script';
+
+-- qualified name
+"billing"."schema_script"
+
+-- rebind
+DELETE FROM "schema_lock" WHERE id = ? AND owner = ?;
+
+-- insert (returns id: no)
+INSERT INTO "schema_script" (script_name, executed_at) VALUES (?, ?);
+
+-- backslash escapes: no
+
+-- advisory lock
+
+
+-- advisory unlock
+
@@ -0,0 +1,60 @@
+-- create script table
+IF OBJECT_ID(N'[schema_script]', N'U') IS NULL
+CREATE TABLE [schema_script] (
+  id BIGINT IDENTITY(1,1) NOT NULL PRIMARY KEY,
+  script_name NVARCHAR(255) NOT NULL,
+  executed_at DATETIME2 NOT NULL,
+  execution_status NVARCHAR(100) NOT NULL,
+  app_version NVARCHAR(30) NULL,
+  error_msg NVARCHAR(MAX) NULL,
+  checksum NVARCHAR(64) NOT NULL DEFAULT '',
+  duration_ms BIGINT NOT NULL DEFAULT 0,
+  executed_by NVARCHAR(255) NOT NULL DEFAULT ''
+);
+
+-- create lock table
+IF OBJECT_ID(N'[schema_lock]', N'U') IS NULL
+CREATE TABLE [schema_lock] (
+  id INTEGER NOT NULL PRIMARY KEY,
+  owner NVARCHAR(255) NOT NULL,
+  acquired_at DATETIME2 NOT NULL,
+  expires_at DATETIME2 NOT NULL
+);
+
+-- create meta table
+IF OBJECT_ID(N'[schema_meta]', N'U') IS NULL
+CREATE TABLE [schema_meta] (
+  id INTEGER NOT NULL PRIMARY KEY,
+  version INTEGER NOT NULL
+);
+
+-- add column
+ALTER TABLE [schema_script] ADD checksum VARCHAR(64) NOT NULL DEFAULT '';
+
+-- drop table
+DROP TABLE IF EXISTS [schema_script];
+
+-- table exists
+SELECT count(*) FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = SCHEMA_NAME() AND TABLE_NAME = 'schema_script';
+
+-- table exists in namespace
+SELECT count(*) FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = 'billing' AND TABLE_NAME = 'schema_script';
+
+-- qualified name
+[billing].[schema_script]
+
+-- rebind
+DELETE FROM [schema_lock] WHERE id = @p1 AND owner = @p2;
+
+-- insert (returns id: yes)
+INSERT INTO [schema_script] (script_name, executed_at) OUTPUT INSERTED.id VALUES (@p1, @p2);
+
+-- backslash escapes: no
+
+-- advisory lock
+DECLARE @result INT;
+EXEC @result = sp_getapplock @Resource = N'billing.schema_lock', @LockMode = 'Exclusive', @LockOwner = 'Session', @LockTimeout = 0;
+SELECT CASE WHEN @result >= 0 THEN 1 ELSE 0 END;
+
+-- advisory unlock
+EXEC sp_releaseapplock @Resource = N'billing.schema_lock', @LockOwner = 'Session';
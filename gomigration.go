@@ -0,0 +1,44 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidMigration is used if a migration written in Go can't be registered, e.g. because of a duplicate name
+	ErrInvalidMigration = errors.New("invalid go migration")
+)
+
+// MigrationFunc is a migration written in Go, e.g. to backfill data which can't be expressed in plain SQL. It gets
+// the transaction it runs in and must not commit or roll it back.
+type MigrationFunc func(ctx context.Context, tx *sql.Tx) error
+
+// migration represents a registered migration written in Go.
+type migration struct {
+	name string
+	up   MigrationFunc
+	down MigrationFunc
+}
+
+// RegisterMigration registers a migration written in Go under the given name. It is sorted by name together with the
+// sql scripts, e.g. "003_backfill" runs after "002_users.sql" and before "004_index.sql", and logged in the same way.
+// The function down is executed by RevertN(), it can be nil if there is nothing to revert.
+// Register all migrations before you call any command.
+func (s *Schema) RegisterMigration(name string, up MigrationFunc, down MigrationFunc) error {
+	if name == "" || up == nil {
+		return fmt.Errorf("%w: name and up function must be provided", ErrInvalidMigration)
+	}
+
+	for _, m := range s.migrations {
+		if m.name == name {
+			return fmt.Errorf("%w: %s is already registered", ErrInvalidMigration, name)
+		}
+	}
+
+	s.migrations = append(s.migrations, &migration{name: name, up: up, down: down})
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package schema_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/rebel-l/schema"
+	"github.com/rebel-l/schema/store"
+	"github.com/rebel-l/schema/utils/testdb"
+)
+
+func backfillItems(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, "INSERT INTO items (name) VALUES ('first'), ('second');")
+
+	return err
+}
+
+func removeItems(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, "DELETE FROM items;")
+
+	return err
+}
+
+func TestSchema_RegisterMigration_Unhappy(t *testing.T) {
+	testCases := []struct {
+		name      string
+		migration string
+		up        schema.MigrationFunc
+	}{
+		{
+			name: "no name",
+			up:   backfillItems,
+		},
+		{
+			name:      "no up function",
+			migration: "002_backfill",
+		},
+		{
+			name:      "duplicate",
+			migration: "001_duplicate",
+			up:        backfillItems,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			s := newSchema(t, nil)
+			if err := s.RegisterMigration("001_duplicate", backfillItems, nil); err != nil {
+				t.Fatalf("Expected no error on first registration but got %s", err)
+			}
+
+			err := s.RegisterMigration(testCase.migration, testCase.up, removeItems)
+			if !errors.Is(err, schema.ErrInvalidMigration) {
+				t.Errorf("Expected error %s but got %v", schema.ErrInvalidMigration, err)
+			}
+		})
+	}
+}
+
+func countItems(t *testing.T, db store.DatabaseConnector) uint32 {
+	t.Helper()
+
+	var counter []uint32
+	if err := db.Select(&counter, "SELECT count(id) FROM items;"); err != nil {
+		t.Fatalf("not able count rows in table: %s", err)
+	}
+
+	return counter[0]
+}
+
+func TestSchema_RegisterMigration_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_go_migration.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	s := newSchema(t, db)
+	if err = s.RegisterMigration("002_backfill", backfillItems, removeItems); err != nil {
+		t.Fatalf("Expected no error on registration but got %s", err)
+	}
+
+	if err = s.Upgrade("./testdata/gomigration", "1.0.0"); err != nil {
+		t.Fatalf("Expected no error on upgrade but got %s", err)
+	}
+
+	data, err := store.NewSchemaScriptMapper(db).GetAll()
+	if err != nil {
+		t.Fatalf("not able get rows from table: %s", err)
+	}
+
+	expected := []string{
+		"001_items.sql",
+		"002_backfill",
+		"003_index.sql",
+	}
+
+	if len(data) != len(expected) {
+		t.Fatalf("Expected %d entries but got %d", len(expected), len(data))
+	}
+
+	for i, v := range expected {
+		if data[i].ScriptName != v || data[i].Status != store.StatusSuccess {
+			t.Errorf("Expected entry %d to be successful execution of %s but got %#v", i, v, data[i])
+		}
+	}
+
+	if got := countItems(t, db); got != 2 {
+		t.Errorf("Expected 2 items after upgrade but got %d", got)
+	}
+
+	if err = s.RevertN("./testdata/gomigration", 2); err != nil {
+		t.Fatalf("Expected no error on revert but got %s", err)
+	}
+
+	if got := countItems(t, db); got != 0 {
+		t.Errorf("Expected 0 items after revert but got %d", got)
+	}
+
+	data, err = store.NewSchemaScriptMapper(db).GetAll()
+	if err != nil {
+		t.Fatalf("not able get rows from table: %s", err)
+	}
+
+	if len(data) != 1 {
+		t.Errorf("Expected 1 entry after revert but got %d", len(data))
+	}
+}
+
+func TestSchema_RegisterMigration_Integration_Unhappy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	testCases := []struct {
+		name     string
+		dbFile   string
+		register string
+		up       schema.MigrationFunc
+		expected error
+		logged   int
+	}{
+		{
+			name:     "failing migration",
+			dbFile:   "./testdata/tmp/schema_go_migration_failing.db",
+			register: "002_backfill",
+			up: func(ctx context.Context, tx *sql.Tx) error {
+				if err := backfillItems(ctx, tx); err != nil {
+					return err
+				}
+
+				return errors.New("failed") // nolint: goerr113
+			},
+			logged: 2,
+		},
+		{
+			name:     "conflict with sql script",
+			dbFile:   "./testdata/tmp/schema_go_migration_conflict.db",
+			register: "001_items.sql",
+			up:       backfillItems,
+			expected: schema.ErrInvalidMigration,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			db, err := testdb.GetDB(testCase.dbFile)
+			if err != nil {
+				t.Fatalf("failed to init database: %s", err)
+			}
+
+			defer testdb.ShutdownDB(db, t)
+
+			s := newSchema(t, db)
+			if err = s.RegisterMigration(testCase.register, testCase.up, nil); err != nil {
+				t.Fatalf("Expected no error on registration but got %s", err)
+			}
+
+			err = s.Upgrade("./testdata/gomigration", "")
+			if err == nil {
+				t.Fatal("Expected error on upgrade")
+			}
+
+			if testCase.expected != nil && !errors.Is(err, testCase.expected) {
+				t.Errorf("Expected error %s but got %s", testCase.expected, err)
+			}
+
+			if testCase.logged == 0 {
+				return
+			}
+
+			data, err := store.NewSchemaScriptMapper(db).GetAll()
+			if err != nil {
+				t.Fatalf("not able get rows from table: %s", err)
+			}
+
+			if len(data) != testCase.logged || data[1].Status != store.StatusError {
+				t.Errorf("Expected %d entries with the last one failed but got %#v", testCase.logged, data)
+			}
+
+			if got := countItems(t, db); got != 0 {
+				t.Errorf("Expected that the migration was rolled back but got %d items", got)
+			}
+		})
+	}
+}
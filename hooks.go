@@ -0,0 +1,157 @@
+package schema
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+)
+
+// EventType identifies the step of a command an Event is emitted for.
+type EventType string
+
+const (
+	// EventBeforeUpgrade is emitted before the pending scripts are applied, Scripts contains their number.
+	EventBeforeUpgrade EventType = "before-upgrade"
+
+	// EventAfterUpgrade is emitted after the pending scripts were applied or one of them failed.
+	EventAfterUpgrade EventType = "after-upgrade"
+
+	// EventBeforeRevert is emitted before the applied scripts are reverted, Scripts contains their number.
+	EventBeforeRevert EventType = "before-revert"
+
+	// EventAfterRevert is emitted after the applied scripts were reverted or one of them failed.
+	EventAfterRevert EventType = "after-revert"
+
+	// EventBeforeScript is emitted before a single script is applied or reverted.
+	EventBeforeScript EventType = "before-script"
+
+	// EventAfterScript is emitted after a single script was applied or reverted.
+	EventAfterScript EventType = "after-script"
+
+	// EventLockWait is emitted each time the lock is held by another process and the command waits for it.
+	EventLockWait EventType = "lock-wait"
+
+	// EventOutOfOrder is emitted by Upgrade() for each pending script older than the newest applied script before
+	// anything is applied, only if OutOfOrderWarn is set.
+	EventOutOfOrder EventType = "out-of-order"
+)
+
+// Event describes a step of a command passed to the subscribers, see WithSubscriber().
+type Event struct {
+	// Type is the step the event is emitted for.
+	Type EventType
+
+	// Script is the name of the script, only set for script and out of order events.
+	Script string
+
+	// Direction is DirectionUp or DirectionDown, not set for lock wait events.
+	Direction string
+
+	// Scripts is the number of scripts the command executes, not set for script and lock wait events.
+	Scripts int
+
+	// AppVersion is the version of your application passed to the command, not set for reverts.
+	AppVersion string
+
+	// Duration is the time the step took for after events or the time waited so far for lock wait events.
+	Duration time.Duration
+
+	// Err is the error of the step for after events or the reason to wait for lock wait events.
+	Err error
+}
+
+// Subscriber is called synchronously for every event of a command, so it delays the command as long as it runs.
+type Subscriber func(event Event)
+
+// WithSubscriber adds a subscriber receiving the events of Upgrade(), RevertN(), Recreate() and MigrateTo(), e.g. to
+// log or time each script. Subscribers are called in the order they were added.
+func WithSubscriber(subscriber Subscriber) Option {
+	return func(s *Schema) error {
+		if subscriber == nil {
+			return fmt.Errorf("%w: subscriber must be provided", ErrInvalidOption)
+		}
+
+		s.subscribers = append(s.subscribers, subscriber)
+
+		return nil
+	}
+}
+
+// WithProgressBar activate the progress bar. It is a subscriber showing the progress of each upgrade or revert.
+func WithProgressBar() Option {
+	return WithSubscriber(progressBar())
+}
+
+// emit passes the event to all subscribers.
+func (s *Schema) emit(event Event) {
+	for _, subscriber := range s.subscribers {
+		subscriber(event)
+	}
+}
+
+// run executes f for each script in the given direction and emits the events around the scripts and each of them.
+func (s *Schema) run(direction string, appVersion string, scripts []*script, f func(sc *script) error) (err error) {
+	before, after := EventBeforeUpgrade, EventAfterUpgrade
+	if direction == DirectionDown {
+		before, after = EventBeforeRevert, EventAfterRevert
+	}
+
+	start := time.Now()
+
+	s.emit(Event{Type: before, Direction: direction, Scripts: len(scripts), AppVersion: appVersion})
+
+	defer func() {
+		s.emit(Event{
+			Type:       after,
+			Direction:  direction,
+			Scripts:    len(scripts),
+			AppVersion: appVersion,
+			Duration:   time.Since(start),
+			Err:        err,
+		})
+	}()
+
+	for _, sc := range scripts {
+		s.emit(Event{Type: EventBeforeScript, Script: sc.name, Direction: direction, AppVersion: appVersion})
+
+		scriptStart := time.Now()
+		err = f(sc)
+
+		s.emit(Event{
+			Type:       EventAfterScript,
+			Script:     sc.name,
+			Direction:  direction,
+			AppVersion: appVersion,
+			Duration:   time.Since(scriptStart),
+			Err:        err,
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// progressBar returns a subscriber showing a progress bar on the command line.
+func progressBar() Subscriber {
+	var bar *pb.ProgressBar
+
+	return func(event Event) {
+		switch event.Type {
+		case EventBeforeUpgrade, EventBeforeRevert:
+			bar = pb.StartNew(event.Scripts)
+		case EventAfterScript:
+			if bar != nil {
+				bar.Increment()
+			}
+		case EventAfterUpgrade, EventAfterRevert:
+			if bar != nil {
+				bar.Finish()
+				bar = nil
+			}
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package schema_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rebel-l/schema"
+	"github.com/rebel-l/schema/mocks/schema_mock"
+	"github.com/rebel-l/schema/store"
+	"github.com/rebel-l/schema/utils/testdb"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestSchema_WithSubscriber_Upgrade(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+
+	mockApplier := schema_mock.NewMockApplier(ctrl)
+	mockApplier.EXPECT().
+		ApplyScriptFSContext(gomock.Any(), gomock.Any(), "002.sql", gomock.Any()).Times(1).
+		DoAndReturn(runCallbacks(mockDB))
+
+	executed := store.SchemaScriptCollection{
+		&store.SchemaScript{ScriptName: "001.sql", Status: store.StatusSuccess},
+	}
+
+	mockScripter := schema_mock.NewMockScripter(ctrl)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(executed, nil)
+	mockScripter.EXPECT().AddWithContext(gomock.Any(), mockDB, gomock.Any()).Return(nil)
+
+	var events []schema.Event
+
+	s := newSchema(t, mockDB,
+		schema.WithoutValidation(),
+		schema.WithSubscriber(func(event schema.Event) {
+			events = append(events, event)
+		}),
+		schema.WithApplier(mockApplier),
+		schema.WithScripter(mockScripter),
+	)
+
+	if err := s.Upgrade("./testdata/unit", ""); err != nil {
+		t.Fatalf("Expected no errors but got %s", err)
+	}
+
+	expected := []string{
+		"before-upgrade up 1 ",
+		"before-script up 0 002.sql",
+		"after-script up 0 002.sql",
+		"after-upgrade up 1 ",
+	}
+
+	if actual := describeEvents(events); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected events %v but got %v", expected, actual)
+	}
+
+	for _, e := range events {
+		if e.Err != nil {
+			t.Errorf("Expected no error on event %s but got %s", e.Type, e.Err)
+		}
+	}
+}
+
+func TestSchema_WithSubscriber_Revert_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, true)
+	failure := errors.New("failed") // nolint: goerr113
+
+	mockApplier := schema_mock.NewMockApplier(ctrl)
+	mockApplier.EXPECT().
+		RevertScriptFSContext(gomock.Any(), gomock.Any(), "002.sql", gomock.Any()).Times(1).
+		Return(failure)
+
+	executed := store.SchemaScriptCollection{
+		&store.SchemaScript{ScriptName: "001.sql", Status: store.StatusSuccess},
+		&store.SchemaScript{ScriptName: "002.sql", Status: store.StatusSuccess},
+	}
+
+	mockScripter := schema_mock.NewMockScripter(ctrl)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(executed, nil)
+
+	var events []schema.Event
+
+	s := newSchema(t, mockDB,
+		schema.WithSubscriber(func(event schema.Event) {
+			events = append(events, event)
+		}),
+		schema.WithApplier(mockApplier),
+		schema.WithScripter(mockScripter),
+	)
+
+	if err := s.RevertAll("./testdata/unit"); !errors.Is(err, failure) {
+		t.Fatalf("Expected error %s but got %v", failure, err)
+	}
+
+	expected := []string{
+		"before-revert down 2 ",
+		"before-script down 0 002.sql",
+		"after-script down 0 002.sql",
+		"after-revert down 2 ",
+	}
+
+	if actual := describeEvents(events); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected events %v but got %v", expected, actual)
+	}
+
+	if !errors.Is(events[2].Err, failure) || !errors.Is(events[3].Err, failure) {
+		t.Errorf("Expected the error on the after events but got %v and %v", events[2].Err, events[3].Err)
+	}
+}
+
+func TestSchema_WithSubscriber_LockWait(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Exec(gomock.Any()).Return(nil, nil)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+
+	mockScripter := schema_mock.NewMockScripter(ctrl)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(store.SchemaScriptCollection{}, nil)
+
+	mockLocker := schema_mock.NewMockLocker(ctrl)
+	first := mockLocker.EXPECT().AcquireContext(gomock.Any(), gomock.Any(), gomock.Any()).Return(store.ErrLocked)
+	mockLocker.EXPECT().AcquireContext(gomock.Any(), gomock.Any(), gomock.Any()).After(first).Return(nil)
+	mockLocker.EXPECT().ReleaseContext(gomock.Any(), gomock.Any()).Return(nil)
+
+	var events []schema.Event
+
+	s := newSchema(t, mockDB,
+		schema.WithLock(5*time.Second),
+		schema.WithSubscriber(func(event schema.Event) {
+			events = append(events, event)
+		}),
+		schema.WithScripter(mockScripter),
+		schema.WithLocker(mockLocker),
+	)
+
+	if err := s.RevertLast("./testdata/unit"); err != nil {
+		t.Fatalf("Expected no errors but got %s", err)
+	}
+
+	expected := []string{
+		"lock-wait  0 ",
+		"before-revert down 0 ",
+		"after-revert down 0 ",
+	}
+
+	if actual := describeEvents(events); !reflect.DeepEqual(expected, actual) {
+		t.Fatalf("Expected events %v but got %v", expected, actual)
+	}
+
+	if !errors.Is(events[0].Err, store.ErrLocked) {
+		t.Errorf("Expected error %s on lock wait but got %v", store.ErrLocked, events[0].Err)
+	}
+}
+
+func TestSchema_WithSubscriber_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_subscriber.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	var first, second []schema.Event
+
+	s := newSchema(t, db,
+		schema.WithSubscriber(func(event schema.Event) {
+			first = append(first, event)
+		}),
+		schema.WithSubscriber(func(event schema.Event) {
+			second = append(second, event)
+		}),
+	)
+
+	if err = s.Upgrade("./testdata/migrate", ""); err != nil {
+		t.Fatalf("failed to upgrade: %s", err)
+	}
+
+	if err = s.MigrateTo("./testdata/migrate", "1", ""); err != nil {
+		t.Fatalf("failed to migrate: %s", err)
+	}
+
+	expected := []string{
+		"before-upgrade up 3 ",
+		"before-script up 0 001_something.sql",
+		"after-script up 0 001_something.sql",
+		"before-script up 0 002_something_new.sql",
+		"after-script up 0 002_something_new.sql",
+		"before-script up 0 003_something_else.sql",
+		"after-script up 0 003_something_else.sql",
+		"after-upgrade up 3 ",
+		"before-revert down 2 ",
+		"before-script down 0 003_something_else.sql",
+		"after-script down 0 003_something_else.sql",
+		"before-script down 0 002_something_new.sql",
+		"after-script down 0 002_something_new.sql",
+		"after-revert down 2 ",
+		"before-upgrade up 0 ",
+		"after-upgrade up 0 ",
+	}
+
+	if actual := describeEvents(first); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected events %v but got %v", expected, actual)
+	}
+
+	if !reflect.DeepEqual(first, second) {
+		t.Error("Expected all subscribers to receive the same events")
+	}
+
+	for _, e := range first {
+		if e.Type == schema.EventAfterScript && e.Duration <= 0 {
+			t.Errorf("Expected a duration for script %s", e.Script)
+		}
+	}
+}
+
+// describeEvents returns the events without durations and errors, so they can be compared.
+func describeEvents(events []schema.Event) []string {
+	result := make([]string, 0, len(events))
+	for _, e := range events {
+		result = append(result, fmt.Sprintf("%s %s %d %s", e.Type, e.Direction, e.Scripts, e.Script))
+	}
+
+	return result
+}
@@ -2,63 +2,260 @@
 package initdb
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/rebel-l/schema/dialect"
 	"github.com/rebel-l/schema/sqlfile"
 	"github.com/rebel-l/schema/store"
+
+	"github.com/jmoiron/sqlx"
 )
 
 // InitDB provides functionality to initialize the database.
 type InitDB struct {
-	db store.DatabaseConnector
+	db      store.DatabaseConnector
+	dialect dialect.Dialect
+	tables  store.Tables
+	logger  *slog.Logger
 }
 
-// New returns an InitDB struct.
+// New returns an InitDB struct. The dialect is detected from the driver of db.
 func New(db store.DatabaseConnector) *InitDB {
+	return NewWithDialect(db, dialect.Detect(db))
+}
+
+// NewWithDialect returns an InitDB struct using the given dialect.
+func NewWithDialect(db store.DatabaseConnector, d dialect.Dialect) *InitDB {
+	return NewWithTables(db, d, store.DefaultTables())
+}
+
+// NewWithTables returns an InitDB struct using the given dialect and names of the tables.
+func NewWithTables(db store.DatabaseConnector, d dialect.Dialect, tables store.Tables) *InitDB {
 	return &InitDB{
-		db: db,
+		db:      db,
+		dialect: d,
+		tables:  tables,
+		logger:  slog.New(slog.DiscardHandler),
+	}
+}
+
+// WithLogger writes structured records about the executed scripts to the logger: the number of statements and the
+// duration at level debug, failures at level error. Without a logger nothing is logged.
+func (i *InitDB) WithLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
 	}
+
+	i.logger = logger
+}
+
+// Callback is executed after a script was applied or reverted successfully. If the script runs inside a transaction,
+// the callback gets the transaction as executor, so its changes are rolled back together with the script.
+type Callback func(exec sqlx.Execer) error
+
+// Func is a migration written in Go. It gets the transaction it runs in and must not commit or roll it back.
+type Func func(ctx context.Context, tx *sql.Tx) error
+
+var (
+	// ErrNoTransaction is used if a Func should be executed but the database doesn't support transactions
+	ErrNoTransaction = errors.New("database doesn't support transactions")
+)
+
+// ApplyScript appliers a script to the database. The script and the callbacks are executed inside a transaction if
+// the database supports it and the script is not marked with the directive "-- no-transaction".
+func (i *InitDB) ApplyScript(fileName string, callbacks ...Callback) error {
+	return i.ApplyScriptContext(context.Background(), fileName, callbacks...)
+}
+
+// ApplyScriptContext does the same as ApplyScript but stops the execution if the context is done.
+func (i *InitDB) ApplyScriptContext(ctx context.Context, fileName string, callbacks ...Callback) error {
+	return i.ApplyScriptFSContext(ctx, os.DirFS(filepath.Dir(fileName)), filepath.Base(fileName), callbacks...)
+}
+
+// ApplyScriptFS does the same as ApplyScript but for a script of the given file system, e.g. an embed.FS.
+func (i *InitDB) ApplyScriptFS(fsys fs.FS, fileName string, callbacks ...Callback) error {
+	return i.ApplyScriptFSContext(context.Background(), fsys, fileName, callbacks...)
+}
+
+// ApplyScriptFSContext does the same as ApplyScriptFS but stops the execution if the context is done.
+func (i *InitDB) ApplyScriptFSContext(ctx context.Context, fsys fs.FS, fileName string, callbacks ...Callback) error {
+	return i.execute(ctx, fsys, fileName, sqlfile.CommandUpgrade, callbacks)
+}
+
+// RevertScript reverts a script from the database. It uses a transaction in the same way as ApplyScript.
+func (i *InitDB) RevertScript(fileName string, callbacks ...Callback) error {
+	return i.RevertScriptContext(context.Background(), fileName, callbacks...)
 }
 
-// ApplyScript appliers a script to the database.
-func (i *InitDB) ApplyScript(fileName string) error {
-	sqlScript, err := sqlfile.Read(fileName, sqlfile.CommandUpgrade)
+// RevertScriptContext does the same as RevertScript but stops the execution if the context is done.
+func (i *InitDB) RevertScriptContext(ctx context.Context, fileName string, callbacks ...Callback) error {
+	return i.RevertScriptFSContext(ctx, os.DirFS(filepath.Dir(fileName)), filepath.Base(fileName), callbacks...)
+}
+
+// RevertScriptFS does the same as RevertScript but for a script of the given file system, e.g. an embed.FS.
+func (i *InitDB) RevertScriptFS(fsys fs.FS, fileName string, callbacks ...Callback) error {
+	return i.RevertScriptFSContext(context.Background(), fsys, fileName, callbacks...)
+}
+
+// RevertScriptFSContext does the same as RevertScriptFS but stops the execution if the context is done.
+func (i *InitDB) RevertScriptFSContext(ctx context.Context, fsys fs.FS, fileName string, callbacks ...Callback) error {
+	return i.execute(ctx, fsys, fileName, sqlfile.CommandDowngrade, callbacks)
+}
+
+// ApplyFuncContext executes a migration written in Go and afterwards the callbacks inside a transaction. A nil f
+// executes only the callbacks, e.g. for a migration which has nothing to revert.
+func (i *InitDB) ApplyFuncContext(ctx context.Context, f Func, callbacks ...Callback) error {
+	db, ok := i.db.(store.Transactioner)
+	if !ok {
+		return ErrNoTransaction
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	if _, err = i.db.Exec(sqlScript); err != nil {
+	start := time.Now()
+
+	if err = runFunc(ctx, tx, f, callbacks); err != nil {
+		i.logger.Error("go migration failed", slog.Duration("duration", time.Since(start)), slog.Any("error", err))
+
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			return fmt.Errorf("%w, rollback failed: %v", err, rollbackErr)
+		}
+
 		return err
 	}
 
+	i.logger.Debug("go migration executed", slog.Duration("duration", time.Since(start)))
+
+	return tx.Commit()
+}
+
+func runFunc(ctx context.Context, tx *sql.Tx, f Func, callbacks []Callback) error {
+	if f != nil {
+		if err := f(ctx, tx); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range callbacks {
+		if err := c(tx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// executor is implemented by the database connection and by a transaction.
+type executor interface {
+	sqlx.Execer
+	sqlx.ExecerContext
+}
+
+func (i *InitDB) execute(ctx context.Context, fsys fs.FS, fileName string, command string, callbacks []Callback) error {
+	opts := sqlfile.SplitOptions{BackslashEscapes: i.dialect.BackslashEscapes()}
+
+	statements, err := sqlfile.ReadStatementsFSWithOptions(fsys, fileName, command, opts)
+	if err != nil {
+		return err
+	}
+
+	noTransaction, err := sqlfile.HasDirectiveFS(fsys, fileName, sqlfile.DirectiveNoTransaction)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	db, ok := i.db.(store.Transactioner)
+	transaction := ok && !noTransaction
+
+	if transaction {
+		err = runInTransaction(ctx, db, statements, callbacks)
+	} else {
+		err = run(ctx, i.db, statements, callbacks)
+	}
+
+	attrs := []any{
+		slog.String("script", fileName),
+		slog.String("direction", command),
+		slog.Int("statements", len(statements)),
+		slog.Bool("transaction", transaction),
+		slog.Duration("duration", time.Since(start)),
+	}
+
+	if err != nil {
+		i.logger.Error("script execution failed", append(attrs, slog.Any("error", err))...)
+		return err
+	}
+
+	i.logger.Debug("script executed", attrs...)
+
 	return nil
 }
 
-// RevertScript reverts a script from the database.
-func (i *InitDB) RevertScript(fileName string) error {
-	sqlScript, err := sqlfile.Read(fileName, sqlfile.CommandDowngrade)
+// runInTransaction executes the statements and the callbacks inside a transaction, see run().
+func runInTransaction(
+	ctx context.Context,
+	db store.Transactioner,
+	statements []*sqlfile.Statement,
+	callbacks []Callback,
+) error {
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	if _, err = i.db.Exec(sqlScript); err != nil {
+	if err = run(ctx, tx, statements, callbacks); err != nil {
+		// a transaction is rolled back automatically if the context is done
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			return fmt.Errorf("%w, rollback failed: %v", err, rollbackErr)
+		}
+
 		return err
 	}
 
+	return tx.Commit()
+}
+
+// run executes the statements one by one and afterwards the callbacks.
+func run(ctx context.Context, exec executor, statements []*sqlfile.Statement, callbacks []Callback) error {
+	for _, statement := range statements {
+		if _, err := exec.ExecContext(ctx, statement.SQL); err != nil {
+			return fmt.Errorf("statement at line %d: %w", statement.Line, err)
+		}
+	}
+
+	for _, c := range callbacks {
+		if err := c(exec); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-// Init initializes the schema database.
+// Init initializes the schema database. It creates the tables of this package if they don't exist and migrates tables
+// created by former versions of this package to the current layout, see MetaVersion.
 func (i *InitDB) Init() error {
+	version, err := i.metaVersion()
+	if err != nil {
+		return err
+	}
+
 	scripts := []string{
-		`CREATE TABLE IF NOT EXISTS schema_script (
-  			id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT, -- TODO: AUTOINCREMENT is not available in every database
-  			script_name TEXT NOT NULL,
-  			executed_at DATETIME NOT NULL,
-  			execution_status VARCHAR(100) NOT NULL,
-  			app_version CHAR(30) NULL,
-  			error_msg TEXT NULL
-		);`,
+		i.dialect.CreateScriptTable(i.qualified(i.tables.Script)),
+		i.dialect.CreateLockTable(i.qualified(i.tables.Lock)),
+		i.dialect.CreateMetaTable(i.qualified(i.tables.Meta)),
 	}
 
 	for _, q := range scripts {
@@ -67,14 +264,20 @@ func (i *InitDB) Init() error {
 		}
 	}
 
-	return nil
+	if version == 0 {
+		i.logger.Info("tables created", slog.Int("meta_version", MetaVersion))
+
+		return i.storeMetaVersion(MetaVersion)
+	}
+
+	return i.migrate(version)
 }
 
-// ReInit drops created tables and execute Init() again.
+// ReInit drops created tables and execute Init() again. The lock table is kept, so a lock held by the running
+// process survives.
 func (i *InitDB) ReInit() error {
-	q := `DROP TABLE IF EXISTS %s;`
 	scripts := []string{
-		fmt.Sprintf(q, "schema_script"),
+		i.dialect.DropTable(i.qualified(i.tables.Script)),
 	}
 
 	for _, q := range scripts {
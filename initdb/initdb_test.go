@@ -1,19 +1,29 @@
 package initdb_test
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
+	"log/slog"
 	"strings"
 	"testing"
+	"testing/fstest"
 
+	"github.com/rebel-l/schema/dialect"
 	"github.com/rebel-l/schema/store"
 
 	"github.com/golang/mock/gomock"
+	"github.com/jmoiron/sqlx"
 
 	"github.com/rebel-l/schema/initdb"
 	"github.com/rebel-l/schema/mocks/store_mock"
 	"github.com/rebel-l/schema/utils/testdb"
 )
 
+var errFunc = errors.New("go migration failed") // nolint: goerr113
+
 func TestInitDB_ApplyScript_Integration_Happy(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipped because of long running")
@@ -92,6 +102,337 @@ func TestInitDB_ApplyScript_Integration_Unhappy(t *testing.T) {
 	}
 }
 
+func TestInitDB_ApplyScript_Integration_Transaction(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	testCases := []struct {
+		name        string
+		dbFile      string
+		scriptName  string
+		callbackErr error
+		tableExists bool
+	}{
+		{
+			name:       "failing script is rolled back",
+			dbFile:     "./testdata/tmp/apply_script_transaction_rollback.db",
+			scriptName: "./testdata/002_broken.sql",
+		},
+		{
+			name:        "failing callback rolls back script",
+			dbFile:      "./testdata/tmp/apply_script_transaction_callback.db",
+			scriptName:  "./testdata/001.sql",
+			callbackErr: errors.New("callback failed"), // nolint: goerr113
+		},
+		{
+			name:        "script without transaction is not rolled back",
+			dbFile:      "./testdata/tmp/apply_script_transaction_disabled.db",
+			scriptName:  "./testdata/003_broken_no_transaction.sql",
+			tableExists: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		dbFile := testCase.dbFile
+		scriptName := testCase.scriptName
+		callbackErr := testCase.callbackErr
+		tableExists := testCase.tableExists
+		t.Run(testCase.name, func(t *testing.T) {
+			db, err := testdb.InitDB(dbFile)
+			if err != nil {
+				t.Fatalf("Failed to open database: %s", err)
+			}
+
+			defer testdb.ShutdownDB(db, t)
+
+			in := initdb.New(db)
+
+			err = in.ApplyScript(scriptName, func(exec sqlx.Execer) error {
+				return callbackErr
+			})
+			if err == nil {
+				t.Fatal("Expected that error is returned")
+			}
+
+			var counter []uint32
+
+			err = db.Select(&counter, db.Rebind("SELECT count(id) FROM something;"))
+			if tableExists && err != nil {
+				t.Errorf("Expected that table exists but got %s", err)
+			}
+
+			if !tableExists && err == nil {
+				t.Error("Expected that table creation was rolled back")
+			}
+		})
+	}
+}
+
+func TestInitDB_ApplyScript_Integration_Statements(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.InitDB("./testdata/tmp/apply_script_statements_integration.db")
+	if err != nil {
+		t.Fatalf("Failed to open database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	in := initdb.New(db)
+
+	if err = in.ApplyScript("./testdata/004_literal.sql"); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	var txt []string
+
+	if err = db.Select(&txt, "SELECT txt FROM literal;"); err != nil {
+		t.Fatalf("not able to select from table: %s", err)
+	}
+
+	expected := "first line;\n  -- indented line\nlast line"
+	if len(txt) != 1 || txt[0] != expected {
+		t.Errorf("Expected literal %q but got %q", expected, txt)
+	}
+}
+
+func TestInitDB_WithLogger_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.InitDB("./testdata/tmp/apply_script_logger_integration.db")
+	if err != nil {
+		t.Fatalf("Failed to open database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	buf := &bytes.Buffer{}
+
+	in := initdb.New(db)
+	in.WithLogger(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	if err = in.ApplyScript("./testdata/004_literal.sql"); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if err = in.ApplyScript("./testdata/002_broken.sql"); err == nil {
+		t.Fatal("Expected error on broken script")
+	}
+
+	records := parseRecords(t, buf)
+
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records but got %d: %s", len(records), buf.String())
+	}
+
+	if r := records[0]; r.Level != "DEBUG" || r.Script != "004_literal.sql" || r.Direction != "up" ||
+		r.Statements != 2 || !r.Transaction {
+		t.Errorf("Expected debug record of executed script but got %+v", r)
+	}
+
+	if r := records[1]; r.Level != "ERROR" || r.Script != "002_broken.sql" || r.Error == "" {
+		t.Errorf("Expected error record of failed script but got %+v", r)
+	}
+}
+
+// logRecord contains the attributes of a record written by slog.JSONHandler.
+type logRecord struct {
+	Level       string `json:"level"`
+	Msg         string `json:"msg"`
+	Script      string `json:"script"`
+	Direction   string `json:"direction"`
+	Statements  int    `json:"statements"`
+	Transaction bool   `json:"transaction"`
+	Error       string `json:"error"`
+}
+
+func parseRecords(t *testing.T, buf *bytes.Buffer) []logRecord {
+	t.Helper()
+
+	var records []logRecord
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record logRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("failed to parse record %q: %s", line, err)
+		}
+
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func TestInitDB_ApplyScriptFS_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.InitDB("./testdata/tmp/apply_script_fs_integration.db")
+	if err != nil {
+		t.Fatalf("Failed to open database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	fsys := fstest.MapFS{
+		"migrations/001.sql": &fstest.MapFile{
+			Data: []byte("-- up\nCREATE TABLE embedded (id INTEGER);\n-- down\nDROP TABLE embedded;\n"),
+		},
+	}
+
+	in := initdb.New(db)
+
+	if err = in.ApplyScriptFS(fsys, "migrations/001.sql"); err != nil {
+		t.Fatalf("Expected no error on apply but got %s", err)
+	}
+
+	var counter []uint32
+
+	if err = db.Select(&counter, "SELECT count(id) FROM embedded;"); err != nil {
+		t.Fatalf("not able count rows in table: %s", err)
+	}
+
+	if err = in.RevertScriptFS(fsys, "migrations/001.sql"); err != nil {
+		t.Fatalf("Expected no error on revert but got %s", err)
+	}
+
+	if err = db.Select(&counter, "SELECT count(id) FROM embedded;"); err == nil {
+		t.Error("table wasn't dropped")
+	}
+
+	if err = in.ApplyScriptFS(fsys, "migrations/002.sql"); err == nil {
+		t.Error("Expected an error on not existing file")
+	}
+}
+
+func TestInitDB_ApplyScriptFS_BackslashEscapes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fsys := fstest.MapFS{
+		"001.sql": &fstest.MapFile{
+			Data: []byte("-- up\nINSERT INTO quotes VALUES ('It\\'s');\nSELECT 1;\n"),
+		},
+	}
+
+	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
+	first := mockDB.EXPECT().ExecContext(gomock.Any(), "INSERT INTO quotes VALUES ('It\\'s')").Return(nil, nil)
+	mockDB.EXPECT().ExecContext(gomock.Any(), "SELECT 1").After(first).Return(nil, nil)
+
+	in := initdb.NewWithDialect(mockDB, dialect.MySQL{})
+	if err := in.ApplyScriptFS(fsys, "001.sql"); err != nil {
+		t.Errorf("Expected no error but got %s", err)
+	}
+}
+
+func TestInitDB_ApplyScriptContext_Integration_Canceled(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.InitDB("./testdata/tmp/apply_script_context_integration.db")
+	if err != nil {
+		t.Fatalf("Failed to open database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	in := initdb.New(db)
+	if err = in.ApplyScriptContext(ctx, "./testdata/001.sql"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected error %s but got %v", context.Canceled, err)
+	}
+
+	var counter []uint32
+	if err = db.Select(&counter, "SELECT count(id) FROM something;"); err == nil {
+		t.Error("Expected that the script was not applied")
+	}
+}
+
+func TestInitDB_ApplyFuncContext_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	testCases := []struct {
+		name     string
+		f        initdb.Func
+		expected error
+		counter  uint32
+	}{
+		{
+			name: "success",
+			f: func(ctx context.Context, tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx, "INSERT INTO something (id) VALUES (1);")
+				return err
+			},
+			counter: 1,
+		},
+		{
+			name: "no function",
+		},
+		{
+			name: "failure is rolled back",
+			f: func(ctx context.Context, tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, "INSERT INTO something (id) VALUES (1);"); err != nil {
+					return err
+				}
+
+				return errFunc
+			},
+			expected: errFunc,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			db, err := testdb.InitDB("./testdata/tmp/apply_func_integration.db")
+			if err != nil {
+				t.Fatalf("Failed to open database: %s", err)
+			}
+
+			defer testdb.ShutdownDB(db, t)
+
+			in := initdb.New(db)
+			if err = in.ApplyScript("./testdata/001.sql"); err != nil {
+				t.Fatalf("Expected no error on applying script but got %s", err)
+			}
+
+			called := false
+			err = in.ApplyFuncContext(context.Background(), testCase.f, func(exec sqlx.Execer) error {
+				called = true
+				return nil
+			})
+
+			if !errors.Is(err, testCase.expected) {
+				t.Errorf("Expected error %v but got %v", testCase.expected, err)
+			}
+
+			if called == (testCase.expected != nil) {
+				t.Errorf("Expected callback called to be %t", testCase.expected == nil)
+			}
+
+			var counter []uint32
+			if err = db.Select(&counter, "SELECT count(id) FROM something;"); err != nil {
+				t.Fatalf("not able count rows in table: %s", err)
+			}
+
+			if counter[0] != testCase.counter {
+				t.Errorf("Expected %d rows but got %d", testCase.counter, counter[0])
+			}
+		})
+	}
+}
+
 func TestInitDB_RevertScript_Integration_Happy(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipped because of long running")
@@ -171,7 +512,7 @@ func getMockDB(t *testing.T, errorMsg string) (*gomock.Controller, *store_mock.M
 	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
 
 	if errorMsg != "" {
-		mockDB.EXPECT().Exec(gomock.Any()).Return(nil, errors.New("something happened")) // nolint: goerr113
+		mockDB.EXPECT().ExecContext(gomock.Any(), gomock.Any()).Return(nil, errors.New("something happened")) // nolint: goerr113
 	}
 
 	return ctrl, mockDB
@@ -181,17 +522,37 @@ func TestInitDB_Init_Happy(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	q := `CREATE TABLE IF NOT EXISTS schema_script (
-  			id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT, -- TODO: AUTOINCREMENT is not available in every database
-  			script_name TEXT NOT NULL,
-  			executed_at DATETIME NOT NULL,
-  			execution_status VARCHAR(100) NOT NULL,
-  			app_version CHAR(30) NULL,
-  			error_msg TEXT NULL
-		);`
+	q := `CREATE TABLE IF NOT EXISTS "schema_script" (
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  script_name TEXT NOT NULL,
+  executed_at DATETIME NOT NULL,
+  execution_status VARCHAR(100) NOT NULL,
+  app_version CHAR(30) NULL,
+  error_msg TEXT NULL,
+  checksum CHAR(64) NOT NULL DEFAULT '',
+  duration_ms INTEGER NOT NULL DEFAULT 0,
+  executed_by TEXT NOT NULL DEFAULT ''
+);`
+
+	qLock := `CREATE TABLE IF NOT EXISTS "schema_lock" (
+  id INTEGER NOT NULL PRIMARY KEY,
+  owner TEXT NOT NULL,
+  acquired_at DATETIME NOT NULL,
+  expires_at DATETIME NOT NULL
+);`
+
+	qMeta := `CREATE TABLE IF NOT EXISTS "schema_meta" (
+  id INTEGER NOT NULL PRIMARY KEY,
+  version INTEGER NOT NULL
+);`
 
 	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
+	expectFreshDB(mockDB)
 	mockDB.EXPECT().Exec(q).Return(nil, nil)
+	mockDB.EXPECT().Exec(qLock).Return(nil, nil)
+	mockDB.EXPECT().Exec(qMeta).Return(nil, nil)
+	mockDB.EXPECT().Exec(`DELETE FROM "schema_meta";`).Return(nil, nil)
+	mockDB.EXPECT().Exec(`INSERT INTO "schema_meta" (id, version) VALUES (1, ?);`, initdb.MetaVersion).Return(nil, nil)
 
 	in := initdb.New(mockDB)
 	if err := in.Init(); err != nil {
@@ -199,11 +560,20 @@ func TestInitDB_Init_Happy(t *testing.T) {
 	}
 }
 
+// expectFreshDB lets the checks for existing tables return that no table exists.
+func expectFreshDB(mockDB *store_mock.MockDatabaseConnector) {
+	mockDB.EXPECT().
+		Select(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(errors.New("no such table")) // nolint: goerr113
+}
+
 func TestInitDB_Init_Unhappy(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
+	expectFreshDB(mockDB)
 	mockDB.EXPECT().Exec(gomock.Any()).Return(nil, errors.New("something happened")) // nolint: goerr113
 
 	in := initdb.New(mockDB)
@@ -243,25 +613,125 @@ func TestInitDB_Init_Integration_Happy(t *testing.T) {
 	if len(counter) == 0 || counter[0] != 0 {
 		t.Error("not able to select from table")
 	}
+
+	checkMetaVersion(t, db, initdb.MetaVersion)
+}
+
+func TestInitDB_Init_Integration_Migrate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	testCases := []struct {
+		name   string
+		layout string
+	}{
+		{
+			name: "initial layout",
+			layout: `CREATE TABLE schema_script (
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  script_name TEXT NOT NULL,
+  executed_at DATETIME NOT NULL,
+  execution_status VARCHAR(100) NOT NULL,
+  app_version CHAR(30) NULL,
+  error_msg TEXT NULL
+);`,
+		},
+		{
+			name: "layout with checksum",
+			layout: `CREATE TABLE schema_script (
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  script_name TEXT NOT NULL,
+  executed_at DATETIME NOT NULL,
+  execution_status VARCHAR(100) NOT NULL,
+  app_version CHAR(30) NULL,
+  error_msg TEXT NULL,
+  checksum CHAR(64) NOT NULL DEFAULT ''
+);`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			db, err := testdb.GetDB("./testdata/tmp/init_migrate_integration.db")
+			if err != nil {
+				t.Fatalf("not able to open database connection: %s", err)
+			}
+
+			defer testdb.ShutdownDB(db, t)
+
+			// prepare a table created by a former version
+			if _, err = db.Exec(testCase.layout); err != nil {
+				t.Fatalf("Prepare: failed to create table: %s", err)
+			}
+
+			q := `INSERT INTO schema_script (script_name, executed_at, execution_status, app_version, error_msg)
+VALUES ('001.sql', '2020-01-01T00:00:00Z', 'success', '1.0.0', '');`
+			if _, err = db.Exec(q); err != nil {
+				t.Fatalf("Prepare: failed to add entry: %s", err)
+			}
+
+			// now the test, the second run must not change anything
+			in := initdb.New(db)
+			for i := 0; i < 2; i++ {
+				if err = in.Init(); err != nil {
+					t.Fatalf("Expected no error on run %d but got %s", i+1, err)
+				}
+			}
+
+			checkMetaVersion(t, db, initdb.MetaVersion)
+
+			m := store.NewSchemaScriptMapper(db)
+			if err = m.Add(store.NewSchemaScriptSuccess("002.sql", "")); err != nil {
+				t.Fatalf("Failed to add entry to migrated table: %s", err)
+			}
+
+			data, err := m.GetAll()
+			if err != nil {
+				t.Fatalf("Failed to read migrated table: %s", err)
+			}
+
+			if data.Len() != 2 || data[0].ScriptName != "001.sql" || data[0].AppVersion != "1.0.0" {
+				t.Fatalf("Expected existing entry to be kept but got %d entries", data.Len())
+			}
+
+			if data[0].Checksum != "" || data[0].DurationMS != 0 || data[0].ExecutedBy != "" {
+				t.Errorf("Expected defaults for the new columns of the existing entry but got %#v", data[0])
+			}
+
+			if data[1].ExecutedBy == "" {
+				t.Error("Expected new columns to be filled for new entries")
+			}
+		})
+	}
+}
+
+func checkMetaVersion(t *testing.T, db store.DatabaseConnector, expected int) {
+	t.Helper()
+
+	var versions []int
+	if err := db.Select(&versions, "SELECT version FROM schema_meta;"); err != nil {
+		t.Fatalf("not able to read meta-version: %s", err)
+	}
+
+	if len(versions) != 1 || versions[0] != expected {
+		t.Errorf("Expected meta-version %d but got %v", expected, versions)
+	}
 }
 
 func TestInitDB_ReInit_Happy(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	q1 := `DROP TABLE IF EXISTS schema_script;`
-	q2 := `CREATE TABLE IF NOT EXISTS schema_script (
-  			id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT, -- TODO: AUTOINCREMENT is not available in every database
-  			script_name TEXT NOT NULL,
-  			executed_at DATETIME NOT NULL,
-  			execution_status VARCHAR(100) NOT NULL,
-  			app_version CHAR(30) NULL,
-  			error_msg TEXT NULL
-		);`
+	q1 := `DROP TABLE IF EXISTS "schema_script";`
 
 	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
-	mockDB.EXPECT().Exec(q1).Return(nil, nil)
-	mockDB.EXPECT().Exec(q2).Return(nil, nil)
+	expectFreshDB(mockDB)
+
+	drop := mockDB.EXPECT().Exec(q1).Return(nil, nil)
+	mockDB.EXPECT().Exec(gomock.Any()).Times(4).After(drop).Return(nil, nil)
+	mockDB.EXPECT().Exec(gomock.Any(), initdb.MetaVersion).After(drop).Return(nil, nil)
 
 	in := initdb.New(mockDB)
 	if err := in.ReInit(); err != nil {
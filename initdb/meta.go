@@ -0,0 +1,117 @@
+package initdb
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/rebel-l/schema/dialect"
+)
+
+const (
+	// MetaVersion is the version of the layout of the tables of this package. Init() creates the tables in this layout
+	// and migrates tables created by former versions to it.
+	MetaVersion = 3
+)
+
+// metaMigrations returns the chain of statements upgrading the tables of this package. The first entry upgrades
+// meta-version 1 (the initial layout) to 2, the second one 2 to 3 and so on. Append a new entry and increase
+// MetaVersion to change the layout, never change an existing entry.
+func metaMigrations(d dialect.Dialect, scriptTable string) [][]string {
+	return [][]string{
+		{
+			d.AddColumn(scriptTable, "checksum", "VARCHAR(64) NOT NULL DEFAULT ''"),
+		},
+		{
+			d.AddColumn(scriptTable, "duration_ms", "BIGINT NOT NULL DEFAULT 0"),
+			d.AddColumn(scriptTable, "executed_by", "VARCHAR(255) NOT NULL DEFAULT ''"),
+		},
+	}
+}
+
+// metaVersion returns the meta-version of the existing tables or 0 if the database wasn't initialised. Tables created
+// before the meta-version was introduced are detected by their columns.
+func (i *InitDB) metaVersion() (int, error) {
+	if i.tableExists(i.tables.Meta) {
+		var versions []int
+
+		q := fmt.Sprintf("SELECT version FROM %s WHERE id = 1;", i.qualified(i.tables.Meta))
+		if err := i.db.Select(&versions, q); err != nil {
+			return 0, fmt.Errorf("failed to read meta-version: %w", err)
+		}
+
+		if len(versions) > 0 {
+			return versions[0], nil
+		}
+	}
+
+	if !i.tableExists(i.tables.Script) {
+		return 0, nil
+	}
+
+	if !i.columnExists(i.tables.Script, "checksum") {
+		return 1, nil
+	}
+
+	if !i.columnExists(i.tables.Script, "duration_ms") {
+		return 2, nil
+	}
+
+	return 3, nil
+}
+
+// migrate upgrades the tables from the given meta-version to MetaVersion. The meta-version is stored after each step,
+// so an interrupted migration continues with the failed step.
+func (i *InitDB) migrate(version int) error {
+	migrations := metaMigrations(i.dialect, i.qualified(i.tables.Script))
+
+	for ; version < MetaVersion; version++ {
+		for _, q := range migrations[version-1] {
+			if _, err := i.db.Exec(q); err != nil {
+				return fmt.Errorf("failed to migrate meta-version %d to %d: %w", version, version+1, err)
+			}
+		}
+
+		if err := i.storeMetaVersion(version + 1); err != nil {
+			return err
+		}
+
+		i.logger.Info("tables migrated", slog.Int("from_meta_version", version), slog.Int("to_meta_version", version+1))
+	}
+
+	return nil
+}
+
+// storeMetaVersion replaces the meta-version stored in the meta table.
+func (i *InitDB) storeMetaVersion(version int) error {
+	if _, err := i.db.Exec(fmt.Sprintf("DELETE FROM %s;", i.qualified(i.tables.Meta))); err != nil {
+		return fmt.Errorf("failed to store meta-version: %w", err)
+	}
+
+	q := i.dialect.Rebind(fmt.Sprintf("INSERT INTO %s (id, version) VALUES (1, ?);", i.qualified(i.tables.Meta)))
+	if _, err := i.db.Exec(q, version); err != nil {
+		return fmt.Errorf("failed to store meta-version: %w", err)
+	}
+
+	return nil
+}
+
+func (i *InitDB) tableExists(table string) bool {
+	var counter []uint32
+
+	if err := i.db.Select(&counter, i.dialect.TableExists(i.tables.Namespace, table)); err != nil {
+		return false
+	}
+
+	return len(counter) > 0 && counter[0] > 0
+}
+
+func (i *InitDB) columnExists(table string, column string) bool {
+	var values []string
+
+	return i.db.Select(&values, fmt.Sprintf("SELECT %s FROM %s WHERE 1 = 0;", column, i.qualified(table))) == nil
+}
+
+// qualified returns the quoted name of the table including the namespace.
+func (i *InitDB) qualified(table string) string {
+	return i.tables.Qualified(i.dialect, table)
+}
@@ -0,0 +1,6 @@
+-- up
+CREATE TABLE IF NOT EXISTS something(id INTEGER);
+INSERT INTO not_existing (id) VALUES (1);
+
+-- down
+DROP TABLE IF EXISTS something;
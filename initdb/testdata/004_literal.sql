@@ -0,0 +1,8 @@
+-- up
+CREATE TABLE IF NOT EXISTS literal(id INTEGER, txt TEXT);
+INSERT INTO literal (id, txt) VALUES (1, 'first line;
+  -- indented line
+last line');
+
+-- down
+DROP TABLE IF EXISTS literal;
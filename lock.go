@@ -0,0 +1,225 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/rebel-l/schema/store"
+)
+
+const (
+	// DefaultLockTTL is the time after which a lock is treated as stale and can be taken over by another process.
+	DefaultLockTTL = 15 * time.Minute
+
+	// MinLockTTL is the smallest ttl accepted by WithLockTTL(), as the lock is extended several times within its ttl.
+	MinLockTTL = 10 * time.Millisecond
+
+	lockRetryInterval = 500 * time.Millisecond
+
+	// lockExtensions is the number of times the lock is extended within its ttl while a command is running
+	lockExtensions = 3
+)
+
+// Locker provides methods to prevent concurrent changes of the database schema, e.g. by several instances of
+// your application starting at the same time.
+type Locker interface {
+	AcquireContext(ctx context.Context, owner string, ttl time.Duration) error
+	ExtendContext(ctx context.Context, owner string, ttl time.Duration) error
+	ReleaseContext(ctx context.Context, owner string) error
+	ForceRelease() error
+}
+
+// WithLock activates locking: Upgrade, RevertN and Recreate acquire a lock before they change anything and release
+// it afterwards. If the lock is held by another process, they wait up to the given timeout for it.
+func WithLock(timeout time.Duration) Option {
+	return func(s *Schema) error {
+		if timeout < 0 {
+			return fmt.Errorf("%w: lock timeout must not be negative", ErrInvalidOption)
+		}
+
+		s.locking = true
+		s.lockTimeout = timeout
+
+		return nil
+	}
+}
+
+// WithAdvisoryLock activates locking like WithLock but holds the lock as advisory lock of the database instead of a
+// row in the lock table, see store.SchemaAdvisoryLocker. New fails with ErrInvalidOption if the database doesn't
+// support advisory locks, e.g. SQLite.
+func WithAdvisoryLock(timeout time.Duration) Option {
+	return func(s *Schema) error {
+		if err := WithLock(timeout)(s); err != nil {
+			return err
+		}
+
+		s.advisoryLock = true
+
+		return nil
+	}
+}
+
+// WithLockTTL sets the time after which a lock is treated as stale, e.g. because the process holding it crashed.
+// A stale lock is taken over by the next process trying to acquire it. While a command is running, the lock is
+// extended regularly, so it doesn't expire during long migrations. Default is DefaultLockTTL, it must be at least
+// MinLockTTL.
+func WithLockTTL(ttl time.Duration) Option {
+	return func(s *Schema) error {
+		if ttl < MinLockTTL {
+			return fmt.Errorf("%w: lock ttl must be at least %s", ErrInvalidOption, MinLockTTL)
+		}
+
+		s.lockTTL = ttl
+
+		return nil
+	}
+}
+
+// WithLocker replaces the default implementation holding the lock in the database, e.g. by advisory locks.
+func WithLocker(locker Locker) Option {
+	return func(s *Schema) error {
+		if locker == nil {
+			return fmt.Errorf("%w: locker must be provided", ErrInvalidOption)
+		}
+
+		s.locker = locker
+
+		return nil
+	}
+}
+
+// ForceUnlock removes the lock independent of the process holding it. Use it to clean up after a crashed process.
+func (s *Schema) ForceUnlock() error {
+	return s.locker.ForceRelease()
+}
+
+// withLock executes f while holding the lock if locking is activated. The context passed to f is limited by the
+// timeout of the whole command.
+func (s *Schema) withLock(ctx context.Context, f func(ctx context.Context) error) (err error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	if !s.locking {
+		return f(ctx)
+	}
+
+	if err = s.createLockTable(); err != nil {
+		return err
+	}
+
+	if err = s.lock(ctx); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	stop := s.keepLock(ctx, cancel)
+
+	defer func() {
+		stop()
+		cancel(nil)
+
+		// the lock is released even if the context was cancelled or timed out
+		releaseErr := s.locker.ReleaseContext(context.WithoutCancel(ctx), s.lockOwner)
+		if releaseErr != nil && err == nil {
+			err = releaseErr
+		}
+	}()
+
+	// tables of former versions are migrated while holding the lock, so concurrent processes don't migrate them twice
+	if err = s.init(); err != nil {
+		return err
+	}
+
+	err = f(ctx)
+	if cause := context.Cause(ctx); err != nil && cause != nil && !errors.Is(err, cause) {
+		err = fmt.Errorf("%w: %w", err, cause)
+	}
+
+	return err
+}
+
+// keepLock extends the lock regularly until the returned function is called. If the lock can't be extended, the
+// context is cancelled with the error as cause, so the command stops before another process takes the lock over.
+func (s *Schema) keepLock(ctx context.Context, cancel context.CancelCauseFunc) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(s.lockTTL / lockExtensions)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.locker.ExtendContext(ctx, s.lockOwner, s.lockTTL); err != nil {
+					cancel(fmt.Errorf("failed to extend lock: %w", err))
+					return
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// createLockTable creates the lock table if it doesn't exist. It is the only table created before the lock is
+// acquired, the other tables are created or migrated by init() while holding the lock.
+func (s *Schema) createLockTable() error {
+	_, err := s.db.Exec(s.dialect.CreateLockTable(s.tables.Qualified(s.dialect, s.tables.Lock)))
+
+	return err
+}
+
+func (s *Schema) lock(ctx context.Context) error {
+	start := time.Now()
+	deadline := start.Add(s.lockTimeout)
+
+	for {
+		err := s.locker.AcquireContext(ctx, s.lockOwner, s.lockTTL)
+		if err == nil || !errors.Is(err, store.ErrLocked) {
+			return err
+		}
+
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return err
+		}
+
+		if wait > lockRetryInterval {
+			wait = lockRetryInterval
+		}
+
+		s.emit(Event{Type: EventLockWait, Duration: time.Since(start), Err: err})
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%v: %w", err, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+func newLockOwner() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+
+	return fmt.Sprintf("%s:%d:%d", host, os.Getpid(), time.Now().UnixNano())
+}
@@ -0,0 +1,310 @@
+package schema_test
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/rebel-l/schema"
+	"github.com/rebel-l/schema/initdb"
+	"github.com/rebel-l/schema/mocks/schema_mock"
+	"github.com/rebel-l/schema/store"
+	"github.com/rebel-l/schema/utils/testdb"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestSchema_WithLock_Happy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Exec(gomock.Any()).Return(nil, nil)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+
+	executed := store.SchemaScriptCollection{
+		&store.SchemaScript{ScriptName: "001.sql", Status: store.StatusSuccess},
+		&store.SchemaScript{ScriptName: "002.sql", Status: store.StatusSuccess},
+	}
+
+	mockScripter := schema_mock.NewMockScripter(ctrl)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(executed, nil)
+
+	mockLocker := schema_mock.NewMockLocker(ctrl)
+	acquire := mockLocker.EXPECT().AcquireContext(gomock.Any(), gomock.Any(), schema.DefaultLockTTL).Times(1).Return(nil)
+	mockLocker.EXPECT().ReleaseContext(gomock.Any(), gomock.Any()).Times(1).After(acquire).Return(nil)
+
+	s := newSchema(t, mockDB,
+		schema.WithLock(time.Second),
+		schema.WithScripter(mockScripter),
+		schema.WithLocker(mockLocker),
+	)
+
+	if err := s.Upgrade("./testdata/unit", ""); err != nil {
+		t.Errorf("Expected no errors but got %s", err)
+	}
+}
+
+func TestSchema_WithLock_Happy_Retry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Exec(gomock.Any()).Return(nil, nil)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+
+	mockScripter := schema_mock.NewMockScripter(ctrl)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(store.SchemaScriptCollection{}, nil)
+
+	mockLocker := schema_mock.NewMockLocker(ctrl)
+	first := mockLocker.EXPECT().AcquireContext(gomock.Any(), gomock.Any(), time.Minute).Return(store.ErrLocked)
+	mockLocker.EXPECT().AcquireContext(gomock.Any(), gomock.Any(), time.Minute).After(first).Return(nil)
+	mockLocker.EXPECT().ReleaseContext(gomock.Any(), gomock.Any()).Return(nil)
+
+	s := newSchema(t, mockDB,
+		schema.WithLock(5*time.Second),
+		schema.WithLockTTL(time.Minute),
+		schema.WithScripter(mockScripter),
+		schema.WithLocker(mockLocker),
+	)
+
+	if err := s.RevertLast("./testdata/unit"); err != nil {
+		t.Errorf("Expected no errors but got %s", err)
+	}
+}
+
+func TestSchema_WithLock_Extend(t *testing.T) {
+	testCases := []struct {
+		name      string
+		extendErr error
+	}{
+		{
+			name: "lock extended",
+		},
+		{
+			name:      "lock lost",
+			extendErr: store.ErrNotLockOwner,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDB := getMockDB(ctrl, false)
+			mockDB.EXPECT().Exec(gomock.Any()).Return(nil, nil)
+			mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+
+			mockScripter := schema_mock.NewMockScripter(ctrl)
+			mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(store.SchemaScriptCollection{}, nil)
+			mockScripter.EXPECT().AddContext(gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+
+			mockApplier := schema_mock.NewMockApplier(ctrl)
+			mockApplier.EXPECT().
+				ApplyScriptFSContext(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				AnyTimes().
+				DoAndReturn(func(ctx context.Context, _ fs.FS, _ string, _ ...initdb.Callback) error {
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case <-time.After(100 * time.Millisecond):
+						return nil
+					}
+				})
+
+			mockLocker := schema_mock.NewMockLocker(ctrl)
+			mockLocker.EXPECT().AcquireContext(gomock.Any(), gomock.Any(), 30*time.Millisecond).Return(nil)
+			mockLocker.EXPECT().ExtendContext(gomock.Any(), gomock.Any(), 30*time.Millisecond).
+				MinTimes(1).Return(testCase.extendErr)
+			mockLocker.EXPECT().ReleaseContext(gomock.Any(), gomock.Any()).Return(nil)
+
+			s := newSchema(t, mockDB,
+				schema.WithLock(time.Second),
+				schema.WithLockTTL(30*time.Millisecond),
+				schema.WithApplier(mockApplier),
+				schema.WithScripter(mockScripter),
+				schema.WithLocker(mockLocker),
+			)
+
+			err := s.Upgrade("./testdata/unit", "")
+			if testCase.extendErr == nil && err != nil {
+				t.Errorf("Expected no errors but got %s", err)
+			}
+
+			if testCase.extendErr != nil && !errors.Is(err, testCase.extendErr) {
+				t.Errorf("Expected error %s but got %v", testCase.extendErr, err)
+			}
+		})
+	}
+}
+
+func TestSchema_WithAdvisoryLock_Unhappy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, err := schema.New(getMockDB(ctrl, true), schema.WithAdvisoryLock(time.Second))
+	if !errors.Is(err, schema.ErrInvalidOption) {
+		t.Errorf("Expected error %s but got %v", schema.ErrInvalidOption, err)
+	}
+}
+
+func TestSchema_WithLock_Unhappy(t *testing.T) {
+	testCases := []struct {
+		name       string
+		acquireErr error
+		releaseErr error
+		expected   error
+	}{
+		{
+			name:       "locked by other process",
+			acquireErr: store.ErrLocked,
+			expected:   store.ErrLocked,
+		},
+		{
+			name:       "failed to acquire",
+			acquireErr: errors.New("failed acquire"), // nolint: goerr113
+		},
+		{
+			name:       "failed to release",
+			releaseErr: errors.New("failed release"), // nolint: goerr113
+		},
+	}
+
+	for _, testCase := range testCases {
+		acquireErr := testCase.acquireErr
+		releaseErr := testCase.releaseErr
+		expected := testCase.expected
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDB := getMockDB(ctrl, false)
+			mockDB.EXPECT().Exec(gomock.Any()).Return(nil, nil)
+			mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+
+			mockScripter := schema_mock.NewMockScripter(ctrl)
+			mockLocker := schema_mock.NewMockLocker(ctrl)
+			mockLocker.EXPECT().AcquireContext(gomock.Any(), gomock.Any(), gomock.Any()).Return(acquireErr)
+
+			if acquireErr == nil {
+				mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(store.SchemaScriptCollection{}, nil)
+				mockLocker.EXPECT().ReleaseContext(gomock.Any(), gomock.Any()).Return(releaseErr)
+			} else {
+				mockScripter.EXPECT().GetAllContext(gomock.Any()).Times(0)
+				mockLocker.EXPECT().ReleaseContext(gomock.Any(), gomock.Any()).Times(0)
+			}
+
+			s := newSchema(t, mockDB,
+				schema.WithLock(0),
+				schema.WithScripter(mockScripter),
+				schema.WithLocker(mockLocker),
+			)
+
+			err := s.RevertAll("./testdata/unit")
+			if err == nil {
+				t.Fatal("Expected error is returned")
+			}
+
+			if expected != nil && !errors.Is(err, expected) {
+				t.Errorf("Expected error %s but got %s", expected, err)
+			}
+		})
+	}
+}
+
+func TestSchema_WithLock_Unhappy_LockTableError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, true)
+	mockDB.EXPECT().Exec(gomock.Any()).Return(nil, errors.New("failed create")) // nolint: goerr113
+
+	mockApplier := schema_mock.NewMockApplier(ctrl)
+	mockApplier.EXPECT().Init().Times(0)
+
+	mockLocker := schema_mock.NewMockLocker(ctrl)
+	mockLocker.EXPECT().AcquireContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	s := newSchema(t, mockDB, schema.WithLock(0), schema.WithApplier(mockApplier), schema.WithLocker(mockLocker))
+
+	if err := s.RevertLast("./testdata/unit"); err == nil {
+		t.Error("Expected error is returned on failed creation of the lock table")
+	}
+}
+
+func TestSchema_WithLock_Unhappy_InitError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Exec(gomock.Any()).Return(nil, nil)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(errors.New("no table")) // nolint: goerr113
+
+	mockLocker := schema_mock.NewMockLocker(ctrl)
+	acquire := mockLocker.EXPECT().AcquireContext(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	mockApplier := schema_mock.NewMockApplier(ctrl)
+	initDB := mockApplier.EXPECT().Init().After(acquire).Return(errors.New("failed init")) // nolint: goerr113
+
+	mockLocker.EXPECT().ReleaseContext(gomock.Any(), gomock.Any()).After(initDB).Return(nil)
+
+	s := newSchema(t, mockDB, schema.WithLock(0), schema.WithApplier(mockApplier), schema.WithLocker(mockLocker))
+
+	if err := s.RevertLast("./testdata/unit"); err == nil {
+		t.Error("Expected error is returned on failed database initialisation")
+	}
+}
+
+func TestSchema_ForceUnlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLocker := schema_mock.NewMockLocker(ctrl)
+	mockLocker.EXPECT().ForceRelease().Return(nil)
+
+	s := newSchema(t, getMockDB(ctrl, true), schema.WithLocker(mockLocker))
+
+	if err := s.ForceUnlock(); err != nil {
+		t.Errorf("Expected no errors but got %s", err)
+	}
+}
+
+func TestSchema_WithLock_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.InitDB("./testdata/tmp/schema_lock.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	if err = store.NewSchemaLockMapper(db).Acquire("other process", time.Hour); err != nil {
+		t.Fatalf("Prepare: failed to acquire lock: %s", err)
+	}
+
+	s := newSchema(t, db, schema.WithLock(0))
+
+	if err = s.Upgrade("./testdata/upgrade/happy", ""); !errors.Is(err, store.ErrLocked) {
+		t.Fatalf("Expected error %s but got %v", store.ErrLocked, err)
+	}
+
+	checkTable("schema_script", db, t, 0)
+
+	if err = s.ForceUnlock(); err != nil {
+		t.Fatalf("failed to force unlock: %s", err)
+	}
+
+	if err = s.Upgrade("./testdata/upgrade/happy", ""); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	checkTable("schema_script", db, t, 2)
+	checkTable("schema_lock", db, t, 0)
+}
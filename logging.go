@@ -0,0 +1,82 @@
+package schema
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// WithLogger writes structured records about the commands to the logger, e.g. which script was applied, how long it
+// took and why it failed. The logger is passed to the default implementations of Scripter and Applier too, they log
+// the number of statements of each script and the changes of the log of script executions at level debug.
+// Without a logger nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Schema) error {
+		if logger == nil {
+			return fmt.Errorf("%w: logger must be provided", ErrInvalidOption)
+		}
+
+		s.logger = logger
+		s.subscribers = append(s.subscribers, logEvents(logger))
+
+		return nil
+	}
+}
+
+// logEvents returns a subscriber writing the events to the logger. Failures are logged at level error, the start and
+// end of commands and scripts at level info, the start of each script at level debug and scripts out of order at level
+// warn.
+func logEvents(logger *slog.Logger) Subscriber {
+	return func(event Event) {
+		attrs := []any{slog.String("direction", event.Direction)}
+		if event.AppVersion != "" {
+			attrs = append(attrs, slog.String("app_version", event.AppVersion))
+		}
+
+		switch event.Type {
+		case EventBeforeUpgrade, EventBeforeRevert:
+			logger.Info(commandName(event)+" started", append(attrs, slog.Int("scripts", event.Scripts))...)
+		case EventAfterUpgrade, EventAfterRevert:
+			attrs = append(attrs, slog.Int("scripts", event.Scripts), slog.Duration("duration", event.Duration))
+			if event.Err != nil {
+				logger.Error(commandName(event)+" failed", append(attrs, slog.Any("error", event.Err))...)
+				return
+			}
+
+			logger.Info(commandName(event)+" finished", attrs...)
+		case EventBeforeScript:
+			logger.Debug("script started", append(attrs, slog.String("script", event.Script))...)
+		case EventAfterScript:
+			attrs = append(attrs, slog.String("script", event.Script), slog.Duration("duration", event.Duration))
+			if event.Err != nil {
+				logger.Error("script failed", append(attrs, slog.Any("error", event.Err))...)
+				return
+			}
+
+			logger.Info("script "+scriptAction(event), attrs...)
+		case EventOutOfOrder:
+			logger.Warn("applying script out of order", append(attrs, slog.String("script", event.Script))...)
+		case EventLockWait:
+			logger.Info(
+				"waiting for lock",
+				slog.Duration("duration", event.Duration),
+				slog.Any("error", event.Err),
+			)
+		}
+	}
+}
+
+func commandName(event Event) string {
+	if event.Direction == DirectionDown {
+		return "revert"
+	}
+
+	return "upgrade"
+}
+
+func scriptAction(event Event) string {
+	if event.Direction == DirectionDown {
+		return "reverted"
+	}
+
+	return "applied"
+}
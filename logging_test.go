@@ -0,0 +1,157 @@
+package schema_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rebel-l/schema"
+	"github.com/rebel-l/schema/mocks/schema_mock"
+	"github.com/rebel-l/schema/store"
+	"github.com/rebel-l/schema/utils/testdb"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestSchema_WithLogger_Upgrade(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	failure := errors.New("failed") // nolint: goerr113
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+
+	mockApplier := schema_mock.NewMockApplier(ctrl)
+	mockApplier.EXPECT().
+		ApplyScriptFSContext(gomock.Any(), gomock.Any(), "001.sql", gomock.Any()).
+		DoAndReturn(runCallbacks(mockDB))
+	mockApplier.EXPECT().
+		ApplyScriptFSContext(gomock.Any(), gomock.Any(), "002.sql", gomock.Any()).
+		Return(failure)
+
+	mockScripter := schema_mock.NewMockScripter(ctrl)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(store.SchemaScriptCollection{}, nil)
+	mockScripter.EXPECT().AddWithContext(gomock.Any(), mockDB, gomock.Any()).Return(nil)
+	mockScripter.EXPECT().AddContext(gomock.Any(), gomock.Any()).Return(nil)
+
+	buf := &bytes.Buffer{}
+
+	s := newSchema(t, mockDB,
+		schema.WithLogger(slog.New(slog.NewJSONHandler(buf, nil))),
+		schema.WithApplier(mockApplier),
+		schema.WithScripter(mockScripter),
+	)
+
+	if err := s.Upgrade("./testdata/unit", "1.0.0"); !errors.Is(err, failure) {
+		t.Fatalf("Expected error %s but got %v", failure, err)
+	}
+
+	expected := []string{
+		"INFO upgrade started up 1.0.0 ",
+		"INFO script applied up 1.0.0 001.sql",
+		"ERROR script failed up 1.0.0 002.sql",
+		"ERROR upgrade failed up 1.0.0 ",
+	}
+
+	if actual := describeRecords(t, buf); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected records %v but got %v", expected, actual)
+	}
+}
+
+func TestSchema_WithLogger_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_logger.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	s := newSchema(t, db, schema.WithLogger(logger))
+	if err = s.Upgrade("./testdata/migrate", "1.0.0"); err != nil {
+		t.Fatalf("failed to upgrade: %s", err)
+	}
+
+	if err = s.RevertLast("./testdata/migrate"); err != nil {
+		t.Fatalf("failed to revert: %s", err)
+	}
+
+	for _, e := range []string{
+		`"msg":"tables created"`,
+		`"msg":"script executed","script":"001_something.sql","direction":"up","statements":1`,
+		`"msg":"script execution logged","script":"001_something.sql","status":"success","app_version":"1.0.0"`,
+		`"msg":"script reverted","direction":"down","script":"003_something_else.sql"`,
+		`"msg":"revert finished","direction":"down","scripts":1`,
+	} {
+		if !strings.Contains(buf.String(), e) {
+			t.Errorf("Expected %s in log but got %s", e, buf.String())
+		}
+	}
+}
+
+func TestSchema_WithLogger_Silent(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_logger_silent.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	buf := &bytes.Buffer{}
+	defaultLogger := slog.Default()
+
+	slog.SetDefault(slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer slog.SetDefault(defaultLogger)
+
+	s := newSchema(t, db)
+	if err = s.Upgrade("./testdata/migrate", ""); err != nil {
+		t.Fatalf("failed to upgrade: %s", err)
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("Expected nothing to be logged without logger but got %s", buf.String())
+	}
+}
+
+// describeRecords returns the records written by slog.JSONHandler without time and duration, so they can be compared.
+func describeRecords(t *testing.T, buf *bytes.Buffer) []string {
+	t.Helper()
+
+	var result []string
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record struct {
+			Level      string `json:"level"`
+			Msg        string `json:"msg"`
+			Direction  string `json:"direction"`
+			AppVersion string `json:"app_version"`
+			Script     string `json:"script"`
+		}
+
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("failed to parse record %q: %s", line, err)
+		}
+
+		result = append(result, strings.Join(
+			[]string{record.Level, record.Msg, record.Direction, record.AppVersion, record.Script},
+			" ",
+		))
+	}
+
+	return result
+}
@@ -0,0 +1,176 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/rebel-l/schema/sqlfile"
+)
+
+var (
+	// ErrUnknownTarget is used if no script matches the target of MigrateTo()
+	ErrUnknownTarget = errors.New("target script not found")
+
+	// ErrAmbiguousTarget is used if several scripts match the target of MigrateTo()
+	ErrAmbiguousTarget = errors.New("target matches several scripts")
+)
+
+// MigrateTo upgrades or reverts the database until the target script is the last applied one. The direction is
+// computed from the applied scripts: applied scripts newer than the target are reverted in descending order, pending
+// scripts up to the target (including it) are applied in ascending order.
+// A path to the sql scripts needs to be provided. It considers only files with ending ".sql", sub folders are ignored
+// unless WithRecursiveScan() is used. Several paths can be separated by os.PathListSeparator, see Upgrade().
+// The target is the name of the script (with or without path and ending) or its version, e.g. "3" for
+// "003_users.sql", see sqlfile.ParseVersion().
+// The version of your application can be provided too, use empty string to ignore it.
+func (s *Schema) MigrateTo(path string, target string, version string) error {
+	return s.MigrateToContext(context.Background(), path, target, version)
+}
+
+// MigrateToContext does the same as MigrateTo but stops if the context is done, see UpgradeContext().
+func (s *Schema) MigrateToContext(ctx context.Context, path string, target string, version string) error {
+	return s.withLock(ctx, func(ctx context.Context) error {
+		return s.migrateTo(ctx, dirSource(path), target, version)
+	})
+}
+
+// MigrateToFS does the same as MigrateTo but takes the scripts from the given file system, see UpgradeFS().
+func (s *Schema) MigrateToFS(fsys fs.FS, target string, version string) error {
+	return s.MigrateToFSContext(context.Background(), fsys, target, version)
+}
+
+// MigrateToFSContext does the same as MigrateToFS but stops if the context is done, see UpgradeContext().
+func (s *Schema) MigrateToFSContext(ctx context.Context, fsys fs.FS, target string, version string) error {
+	return s.withLock(ctx, func(ctx context.Context) error {
+		return s.migrateTo(ctx, fsSource(fsys), target, version)
+	})
+}
+
+func (s *Schema) migrateTo(ctx context.Context, src source, target string, version string) error {
+	if err := s.init(); err != nil {
+		return err
+	}
+
+	executedScripts, err := s.scripter.GetAllContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	scripts, err := src.scan(s.migrations, s.scanOptions)
+	if err != nil {
+		return err
+	}
+
+	if err = s.migrateScriptNames(ctx, scripts, executedScripts); err != nil {
+		return err
+	}
+
+	scripts = versioned(scripts)
+
+	pos, err := findTarget(scripts, target)
+	if err != nil {
+		return err
+	}
+
+	if err = s.checkModifications(scripts, executedScripts); err != nil {
+		return err
+	}
+
+	reverts := make([]*script, 0)
+
+	for i := len(scripts) - 1; i > pos; i-- {
+		if executedScripts.ScriptExecuted(scripts[i].name) {
+			reverts = append(reverts, scripts[i])
+		}
+	}
+
+	applies := make([]*script, 0)
+
+	for _, sc := range scripts[:pos+1] {
+		if !executedScripts.ScriptExecuted(sc.name) {
+			applies = append(applies, sc)
+		}
+	}
+
+	err = s.run(DirectionDown, "", reverts, func(sc *script) error {
+		return s.revertScript(ctx, sc)
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.run(DirectionUp, version, applies, func(sc *script) error {
+		return s.applyScript(ctx, sc, version)
+	})
+}
+
+// findTarget returns the position of the script matching the target.
+func findTarget(scripts []*script, target string) (int, error) {
+	pos := -1
+
+	for i, sc := range scripts {
+		if !matchTarget(sc.name, target) {
+			continue
+		}
+
+		if pos >= 0 {
+			return -1, fmt.Errorf("%w: %s", ErrAmbiguousTarget, target)
+		}
+
+		pos = i
+	}
+
+	if pos < 0 {
+		return -1, fmt.Errorf("%w: %s", ErrUnknownTarget, target)
+	}
+
+	return pos, nil
+}
+
+func matchTarget(fileName string, target string) bool {
+	base := filepath.Base(fileName)
+	if target == fileName || target == base || target == strings.TrimSuffix(base, ".sql") ||
+		strings.HasSuffix(target, "/"+fileName) {
+		return true
+	}
+
+	if !isVersion(target) {
+		return false
+	}
+
+	expected, err := sqlfile.ParseVersion(target)
+	if err != nil {
+		return false
+	}
+
+	actual, err := sqlfile.ParseVersion(base)
+
+	return err == nil && actual.Compare(expected) == 0
+}
+
+// isVersion returns true if the target consists of a version only, e.g. "3", "1.2" or "V1_2".
+func isVersion(target string) bool {
+	allowed := "0123456789."
+	if trimmed := strings.TrimLeft(target, "Vv"); len(trimmed) == len(target)-1 {
+		target, allowed = trimmed, allowed+"_"
+	}
+
+	return target != "" && strings.Trim(target, allowed) == ""
+}
+
+// versioned returns the scripts without the repeatable ones.
+func versioned(scripts []*script) []*script {
+	result := make([]*script, 0, len(scripts))
+
+	for _, sc := range scripts {
+		if !sc.repeatable {
+			result = append(result, sc)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,221 @@
+package schema_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/rebel-l/schema"
+	"github.com/rebel-l/schema/mocks/schema_mock"
+	"github.com/rebel-l/schema/sqlfile"
+	"github.com/rebel-l/schema/store"
+	"github.com/rebel-l/schema/utils/testdb"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestSchema_MigrateTo_Happy(t *testing.T) {
+	testCases := []struct {
+		name     string
+		path     string
+		target   string
+		executed []string
+		applied  []string
+		reverted []string
+	}{
+		{
+			name:    "upgrade to numeric prefix",
+			path:    "./testdata/unit",
+			target:  "1",
+			applied: []string{"001.sql"},
+		},
+		{
+			name:     "upgrade to flyway style version",
+			path:     "./testdata/unit",
+			target:   "V2",
+			executed: []string{"001.sql"},
+			applied:  []string{"002.sql"},
+		},
+		{
+			name:     "upgrade to name without ending",
+			path:     "./testdata/unit",
+			target:   "002",
+			executed: []string{"001.sql"},
+			applied:  []string{"002.sql"},
+		},
+		{
+			name:     "revert to file name",
+			path:     "./testdata/unit",
+			target:   "001.sql",
+			executed: []string{"001.sql", "002.sql"},
+			reverted: []string{"002.sql"},
+		},
+		{
+			name:     "already at target",
+			path:     "./testdata/unit",
+			target:   "./testdata/unit/002.sql",
+			executed: []string{"001.sql", "002.sql"},
+		},
+		{
+			name:    "upgrade to dotted version",
+			path:    "./testdata/dotted",
+			target:  "1.2",
+			applied: []string{"1.1_first.sql", "1.2_second.sql"},
+		},
+		{
+			name:     "revert to dotted version",
+			path:     "./testdata/dotted",
+			target:   "1.2",
+			executed: []string{"1.1_first.sql", "1.2_second.sql", "1.2.3_third.sql"},
+			reverted: []string{"1.2.3_third.sql"},
+		},
+		{
+			name:     "upgrade to dotted version with three parts",
+			path:     "./testdata/dotted",
+			target:   "1.2.3",
+			executed: []string{"1.1_first.sql"},
+			applied:  []string{"1.2_second.sql", "1.2.3_third.sql"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		path := testCase.path
+		target := testCase.target
+		executed := store.SchemaScriptCollection{}
+
+		for _, f := range testCase.executed {
+			executed = append(executed, &store.SchemaScript{ScriptName: f, Status: store.StatusSuccess})
+		}
+
+		applied := testCase.applied
+		reverted := testCase.reverted
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDB := getMockDB(ctrl, false)
+			mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+
+			mockScripter := schema_mock.NewMockScripter(ctrl)
+			mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(executed, nil)
+			mockScripter.EXPECT().AddWithContext(gomock.Any(), mockDB, gomock.Any()).Times(len(applied)).Return(nil)
+			mockScripter.EXPECT().RemoveWithContext(gomock.Any(), mockDB, gomock.Any()).Times(len(reverted)).Return(nil)
+
+			mockApplier := schema_mock.NewMockApplier(ctrl)
+			for _, f := range applied {
+				mockApplier.EXPECT().ApplyScriptFSContext(gomock.Any(), gomock.Any(), filepath.Base(f), gomock.Any()).DoAndReturn(runCallbacks(mockDB))
+			}
+
+			for _, f := range reverted {
+				mockApplier.EXPECT().RevertScriptFSContext(gomock.Any(), gomock.Any(), filepath.Base(f), gomock.Any()).DoAndReturn(runCallbacks(mockDB))
+			}
+
+			s := newSchema(t, mockDB, schema.WithApplier(mockApplier), schema.WithScripter(mockScripter))
+
+			if err := s.MigrateTo(path, target, ""); err != nil {
+				t.Errorf("Expected no error but got %s", err)
+			}
+		})
+	}
+}
+
+func TestSchema_MigrateTo_Unhappy_Target(t *testing.T) {
+	testCases := []struct {
+		name     string
+		path     string
+		target   string
+		expected error
+	}{
+		{
+			name:     "unknown target",
+			path:     "./testdata/unit",
+			target:   "003",
+			expected: schema.ErrUnknownTarget,
+		},
+		{
+			name:     "no numeric prefix",
+			path:     "./testdata/unit",
+			target:   "x",
+			expected: schema.ErrUnknownTarget,
+		},
+		{
+			name:     "duplicate version",
+			path:     "./testdata/migrate_duplicate",
+			target:   "1",
+			expected: sqlfile.ErrDuplicateVersion,
+		},
+	}
+
+	for _, testCase := range testCases {
+		path := testCase.path
+		target := testCase.target
+		expected := testCase.expected
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDB := getMockDB(ctrl, false)
+			mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+
+			mockScripter := schema_mock.NewMockScripter(ctrl)
+			mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(store.SchemaScriptCollection{}, nil)
+
+			mockApplier := schema_mock.NewMockApplier(ctrl)
+			mockApplier.EXPECT().ApplyScriptFSContext(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			mockApplier.EXPECT().RevertScriptFSContext(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+			s := newSchema(t, mockDB, schema.WithApplier(mockApplier), schema.WithScripter(mockScripter))
+
+			if err := s.MigrateTo(path, target, ""); !errors.Is(err, expected) {
+				t.Errorf("Expected error %s but got %v", expected, err)
+			}
+		})
+	}
+}
+
+func TestSchema_MigrateTo_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_migrate_to.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	s := newSchema(t, db)
+
+	if err = s.MigrateTo("./testdata/migrate", "3", "1.0.0"); err != nil {
+		t.Fatalf("failed to upgrade: %s", err)
+	}
+
+	checkTable("something_else", db, t, 0)
+	checkTable("schema_script", db, t, 3)
+
+	if err = s.MigrateTo("./testdata/migrate", "001_something", ""); err != nil {
+		t.Fatalf("failed to revert: %s", err)
+	}
+
+	data, err := store.NewSchemaScriptMapper(db).GetAll()
+	if err != nil {
+		t.Fatalf("not able get rows from table: %s", err)
+	}
+
+	expected := store.SchemaScriptCollection{
+		&store.SchemaScript{
+			ScriptName: "001_something.sql",
+			Status:     store.StatusSuccess,
+			AppVersion: "1.0.0",
+		},
+	}
+
+	checkScriptTable("TestSchema_MigrateTo_Integration", expected, data, t)
+	checkTable("something", db, t, 0)
+
+	var counter []uint32
+	if err = db.Select(&counter, "SELECT count(id) FROM something_new;"); err == nil {
+		t.Error("Expected that table of reverted script was dropped")
+	}
+}
@@ -1,13 +1,18 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/rebel-l/schema (interfaces: Applier,Scripter)
+// Source: github.com/rebel-l/schema (interfaces: Applier,Locker,Scripter)
 
 // Package schema_mock is a generated GoMock package.
 package schema_mock
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
+	sqlx "github.com/jmoiron/sqlx"
+	initdb "github.com/rebel-l/schema/initdb"
 	store "github.com/rebel-l/schema/store"
+	fs "io/fs"
 	reflect "reflect"
+	time "time"
 )
 
 // MockApplier is a mock of Applier interface
@@ -33,18 +38,42 @@ func (m *MockApplier) EXPECT() *MockApplierMockRecorder {
 	return m.recorder
 }
 
-// ApplyScript mocks base method
-func (m *MockApplier) ApplyScript(arg0 string) error {
+// ApplyFuncContext mocks base method
+func (m *MockApplier) ApplyFuncContext(arg0 context.Context, arg1 initdb.Func, arg2 ...initdb.Callback) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ApplyScript", arg0)
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ApplyFuncContext", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// ApplyScript indicates an expected call of ApplyScript
-func (mr *MockApplierMockRecorder) ApplyScript(arg0 interface{}) *gomock.Call {
+// ApplyFuncContext indicates an expected call of ApplyFuncContext
+func (mr *MockApplierMockRecorder) ApplyFuncContext(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyScript", reflect.TypeOf((*MockApplier)(nil).ApplyScript), arg0)
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyFuncContext", reflect.TypeOf((*MockApplier)(nil).ApplyFuncContext), varargs...)
+}
+
+// ApplyScriptFSContext mocks base method
+func (m *MockApplier) ApplyScriptFSContext(arg0 context.Context, arg1 fs.FS, arg2 string, arg3 ...initdb.Callback) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ApplyScriptFSContext", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ApplyScriptFSContext indicates an expected call of ApplyScriptFSContext
+func (mr *MockApplierMockRecorder) ApplyScriptFSContext(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyScriptFSContext", reflect.TypeOf((*MockApplier)(nil).ApplyScriptFSContext), varargs...)
 }
 
 // Init mocks base method
@@ -75,18 +104,102 @@ func (mr *MockApplierMockRecorder) ReInit() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReInit", reflect.TypeOf((*MockApplier)(nil).ReInit))
 }
 
-// RevertScript mocks base method
-func (m *MockApplier) RevertScript(arg0 string) error {
+// RevertScriptFSContext mocks base method
+func (m *MockApplier) RevertScriptFSContext(arg0 context.Context, arg1 fs.FS, arg2 string, arg3 ...initdb.Callback) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RevertScript", arg0)
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "RevertScriptFSContext", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// RevertScript indicates an expected call of RevertScript
-func (mr *MockApplierMockRecorder) RevertScript(arg0 interface{}) *gomock.Call {
+// RevertScriptFSContext indicates an expected call of RevertScriptFSContext
+func (mr *MockApplierMockRecorder) RevertScriptFSContext(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevertScript", reflect.TypeOf((*MockApplier)(nil).RevertScript), arg0)
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevertScriptFSContext", reflect.TypeOf((*MockApplier)(nil).RevertScriptFSContext), varargs...)
+}
+
+// MockLocker is a mock of Locker interface
+type MockLocker struct {
+	ctrl     *gomock.Controller
+	recorder *MockLockerMockRecorder
+}
+
+// MockLockerMockRecorder is the mock recorder for MockLocker
+type MockLockerMockRecorder struct {
+	mock *MockLocker
+}
+
+// NewMockLocker creates a new mock instance
+func NewMockLocker(ctrl *gomock.Controller) *MockLocker {
+	mock := &MockLocker{ctrl: ctrl}
+	mock.recorder = &MockLockerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockLocker) EXPECT() *MockLockerMockRecorder {
+	return m.recorder
+}
+
+// AcquireContext mocks base method
+func (m *MockLocker) AcquireContext(arg0 context.Context, arg1 string, arg2 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AcquireContext indicates an expected call of AcquireContext
+func (mr *MockLockerMockRecorder) AcquireContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireContext", reflect.TypeOf((*MockLocker)(nil).AcquireContext), arg0, arg1, arg2)
+}
+
+// ExtendContext mocks base method
+func (m *MockLocker) ExtendContext(arg0 context.Context, arg1 string, arg2 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExtendContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExtendContext indicates an expected call of ExtendContext
+func (mr *MockLockerMockRecorder) ExtendContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtendContext", reflect.TypeOf((*MockLocker)(nil).ExtendContext), arg0, arg1, arg2)
+}
+
+// ForceRelease mocks base method
+func (m *MockLocker) ForceRelease() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForceRelease")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForceRelease indicates an expected call of ForceRelease
+func (mr *MockLockerMockRecorder) ForceRelease() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForceRelease", reflect.TypeOf((*MockLocker)(nil).ForceRelease))
+}
+
+// ReleaseContext mocks base method
+func (m *MockLocker) ReleaseContext(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseContext", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseContext indicates an expected call of ReleaseContext
+func (mr *MockLockerMockRecorder) ReleaseContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseContext", reflect.TypeOf((*MockLocker)(nil).ReleaseContext), arg0, arg1)
 }
 
 // MockScripter is a mock of Scripter interface
@@ -112,45 +225,130 @@ func (m *MockScripter) EXPECT() *MockScripterMockRecorder {
 	return m.recorder
 }
 
-// Add mocks base method
-func (m *MockScripter) Add(arg0 *store.SchemaScript) error {
+// AddContext mocks base method
+func (m *MockScripter) AddContext(arg0 context.Context, arg1 *store.SchemaScript) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Add", arg0)
+	ret := m.ctrl.Call(m, "AddContext", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Add indicates an expected call of Add
-func (mr *MockScripterMockRecorder) Add(arg0 interface{}) *gomock.Call {
+// AddContext indicates an expected call of AddContext
+func (mr *MockScripterMockRecorder) AddContext(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockScripter)(nil).Add), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddContext", reflect.TypeOf((*MockScripter)(nil).AddContext), arg0, arg1)
 }
 
-// GetAll mocks base method
-func (m *MockScripter) GetAll() (store.SchemaScriptCollection, error) {
+// AddWithContext mocks base method
+func (m *MockScripter) AddWithContext(arg0 context.Context, arg1 sqlx.Execer, arg2 *store.SchemaScript) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAll")
+	ret := m.ctrl.Call(m, "AddWithContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddWithContext indicates an expected call of AddWithContext
+func (mr *MockScripterMockRecorder) AddWithContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWithContext", reflect.TypeOf((*MockScripter)(nil).AddWithContext), arg0, arg1, arg2)
+}
+
+// GetAllContext mocks base method
+func (m *MockScripter) GetAllContext(arg0 context.Context) (store.SchemaScriptCollection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllContext", arg0)
 	ret0, _ := ret[0].(store.SchemaScriptCollection)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAll indicates an expected call of GetAll
-func (mr *MockScripterMockRecorder) GetAll() *gomock.Call {
+// GetAllContext indicates an expected call of GetAllContext
+func (mr *MockScripterMockRecorder) GetAllContext(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllContext", reflect.TypeOf((*MockScripter)(nil).GetAllContext), arg0)
+}
+
+// GetFailedContext mocks base method
+func (m *MockScripter) GetFailedContext(arg0 context.Context) (store.SchemaScriptCollection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFailedContext", arg0)
+	ret0, _ := ret[0].(store.SchemaScriptCollection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFailedContext indicates an expected call of GetFailedContext
+func (mr *MockScripterMockRecorder) GetFailedContext(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFailedContext", reflect.TypeOf((*MockScripter)(nil).GetFailedContext), arg0)
+}
+
+// RemoveByIDContext mocks base method
+func (m *MockScripter) RemoveByIDContext(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveByIDContext", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveByIDContext indicates an expected call of RemoveByIDContext
+func (mr *MockScripterMockRecorder) RemoveByIDContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveByIDContext", reflect.TypeOf((*MockScripter)(nil).RemoveByIDContext), arg0, arg1)
+}
+
+// RemoveContext mocks base method
+func (m *MockScripter) RemoveContext(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveContext", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveContext indicates an expected call of RemoveContext
+func (mr *MockScripterMockRecorder) RemoveContext(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveContext", reflect.TypeOf((*MockScripter)(nil).RemoveContext), arg0, arg1)
+}
+
+// RemoveWithContext mocks base method
+func (m *MockScripter) RemoveWithContext(arg0 context.Context, arg1 sqlx.Execer, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveWithContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveWithContext indicates an expected call of RemoveWithContext
+func (mr *MockScripterMockRecorder) RemoveWithContext(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveWithContext", reflect.TypeOf((*MockScripter)(nil).RemoveWithContext), arg0, arg1, arg2)
+}
+
+// RenameContext mocks base method
+func (m *MockScripter) RenameContext(arg0 context.Context, arg1 string, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameContext", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameContext indicates an expected call of RenameContext
+func (mr *MockScripterMockRecorder) RenameContext(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAll", reflect.TypeOf((*MockScripter)(nil).GetAll))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameContext", reflect.TypeOf((*MockScripter)(nil).RenameContext), arg0, arg1, arg2)
 }
 
-// Remove mocks base method
-func (m *MockScripter) Remove(arg0 string) error {
+// UpdateContext mocks base method
+func (m *MockScripter) UpdateContext(arg0 context.Context, arg1 *store.SchemaScript) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Remove", arg0)
+	ret := m.ctrl.Call(m, "UpdateContext", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Remove indicates an expected call of Remove
-func (mr *MockScripterMockRecorder) Remove(arg0 interface{}) *gomock.Call {
+// UpdateContext indicates an expected call of UpdateContext
+func (mr *MockScripterMockRecorder) UpdateContext(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockScripter)(nil).Remove), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateContext", reflect.TypeOf((*MockScripter)(nil).UpdateContext), arg0, arg1)
 }
@@ -5,6 +5,7 @@
 package store_mock
 
 import (
+	context "context"
 	sql "database/sql"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
@@ -67,6 +68,26 @@ func (mr *MockDatabaseConnectorMockRecorder) Exec(arg0 interface{}, arg1 ...inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exec", reflect.TypeOf((*MockDatabaseConnector)(nil).Exec), varargs...)
 }
 
+// ExecContext mocks base method
+func (m *MockDatabaseConnector) ExecContext(arg0 context.Context, arg1 string, arg2 ...interface{}) (sql.Result, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExecContext", varargs...)
+	ret0, _ := ret[0].(sql.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecContext indicates an expected call of ExecContext
+func (mr *MockDatabaseConnectorMockRecorder) ExecContext(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecContext", reflect.TypeOf((*MockDatabaseConnector)(nil).ExecContext), varargs...)
+}
+
 // Get mocks base method
 func (m *MockDatabaseConnector) Get(arg0 interface{}, arg1 string, arg2 ...interface{}) error {
 	m.ctrl.T.Helper()
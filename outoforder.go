@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rebel-l/schema/sqlfile"
+	"github.com/rebel-l/schema/store"
+)
+
+// OutOfOrderPolicy defines how Upgrade() handles pending scripts which are older than the newest applied script,
+// e.g. after merging two feature branches.
+type OutOfOrderPolicy int
+
+const (
+	// OutOfOrderAllow applies scripts out of order without notice. It is the default.
+	OutOfOrderAllow OutOfOrderPolicy = iota
+
+	// OutOfOrderWarn emits an EventOutOfOrder for each of the scripts before they are applied. It needs a subscriber
+	// or a logger to notice it, see WithSubscriber() and WithLogger().
+	OutOfOrderWarn
+
+	// OutOfOrderReject fails with ErrOutOfOrder before anything is applied.
+	OutOfOrderReject
+)
+
+const (
+	// ProblemOutOfOrder marks a pending script which is older than the newest applied script.
+	ProblemOutOfOrder = "out-of-order"
+)
+
+var (
+	// ErrOutOfOrder is used if pending scripts are older than the newest applied script and OutOfOrderReject is set
+	ErrOutOfOrder = errors.New("pending scripts are older than the newest applied script")
+)
+
+// WithOutOfOrder sets the policy for pending scripts which are older than the newest applied script.
+func WithOutOfOrder(policy OutOfOrderPolicy) Option {
+	return func(s *Schema) error {
+		if policy < OutOfOrderAllow || policy > OutOfOrderReject {
+			return fmt.Errorf("%w: unknown out of order policy %d", ErrInvalidOption, policy)
+		}
+
+		s.outOfOrder = policy
+
+		return nil
+	}
+}
+
+// checkOutOfOrder fails with ErrOutOfOrder if scripts are out of order and OutOfOrderReject is set.
+func (s *Schema) checkOutOfOrder(scripts []*script, executedScripts store.SchemaScriptCollection) error {
+	if s.outOfOrder != OutOfOrderReject {
+		return nil
+	}
+
+	names := outOfOrder(scripts, executedScripts)
+	if len(names) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrOutOfOrder, strings.Join(names, ", "))
+}
+
+// warnOutOfOrder emits an EventOutOfOrder for each script out of order if OutOfOrderWarn is set.
+func (s *Schema) warnOutOfOrder(scripts []*script, executedScripts store.SchemaScriptCollection, appVersion string) {
+	if s.outOfOrder != OutOfOrderWarn {
+		return
+	}
+
+	for _, name := range outOfOrder(scripts, executedScripts) {
+		s.emit(Event{Type: EventOutOfOrder, Script: name, Direction: DirectionUp, AppVersion: appVersion})
+	}
+}
+
+// outOfOrder returns the names of the pending versioned scripts which are older than the newest applied script.
+func outOfOrder(scripts []*script, executedScripts store.SchemaScriptCollection) []string {
+	var newest sqlfile.Version
+
+	for _, e := range executedScripts {
+		if !e.Applied() {
+			continue
+		}
+
+		version, err := sqlfile.ParseVersion(e.ScriptName)
+		if err != nil {
+			continue
+		}
+
+		if newest == nil || version.Compare(newest) > 0 {
+			newest = version
+		}
+	}
+
+	names := make([]string, 0)
+	if newest == nil {
+		return names
+	}
+
+	for _, sc := range scripts {
+		if sc.repeatable || executedScripts.ScriptExecuted(sc.name) {
+			continue
+		}
+
+		version, err := sqlfile.ParseVersion(sc.name)
+		if err == nil && version.Compare(newest) < 0 {
+			names = append(names, sc.name)
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,120 @@
+package schema_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/rebel-l/schema"
+	"github.com/rebel-l/schema/mocks/schema_mock"
+	"github.com/rebel-l/schema/store"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestSchema_WithOutOfOrder(t *testing.T) {
+	testCases := []struct {
+		name     string
+		policy   schema.OutOfOrderPolicy
+		applied  int
+		warning  bool
+		expected error
+	}{
+		{
+			name:    "allow",
+			policy:  schema.OutOfOrderAllow,
+			applied: 1,
+		},
+		{
+			name:    "warn",
+			policy:  schema.OutOfOrderWarn,
+			applied: 1,
+			warning: true,
+		},
+		{
+			name:     "reject",
+			policy:   schema.OutOfOrderReject,
+			expected: schema.ErrOutOfOrder,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDB := getMockDB(ctrl, false)
+			mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+
+			mockApplier := schema_mock.NewMockApplier(ctrl)
+			mockApplier.EXPECT().
+				ApplyScriptFSContext(gomock.Any(), gomock.Any(), "001.sql", gomock.Any()).
+				Times(testCase.applied).
+				Return(nil)
+
+			mockScripter := schema_mock.NewMockScripter(ctrl)
+			mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(store.SchemaScriptCollection{
+				store.NewSchemaScriptSuccess("002.sql", ""),
+			}, nil)
+
+			var buffer bytes.Buffer
+
+			warnings := make([]string, 0)
+
+			s := newSchema(t, mockDB,
+				schema.WithoutValidation(),
+				schema.WithOutOfOrder(testCase.policy),
+				schema.WithApplier(mockApplier),
+				schema.WithScripter(mockScripter),
+				schema.WithLogger(slog.New(slog.NewTextHandler(&buffer, nil))),
+				schema.WithSubscriber(func(event schema.Event) {
+					if event.Type == schema.EventOutOfOrder {
+						warnings = append(warnings, event.Script)
+					}
+				}),
+			)
+
+			err := s.Upgrade("./testdata/unit", "")
+			if !errors.Is(err, testCase.expected) {
+				t.Errorf("Expected error %v but got %v", testCase.expected, err)
+			}
+
+			if warned := len(warnings) == 1 && warnings[0] == "001.sql"; warned != testCase.warning {
+				t.Errorf("Expected warning to be %t but got events for %v", testCase.warning, warnings)
+			}
+
+			logged := strings.Contains(buffer.String(), "level=WARN msg=\"applying script out of order\"")
+			if logged != testCase.warning {
+				t.Errorf("Expected warning to be logged %t but got %q", testCase.warning, buffer.String())
+			}
+		})
+	}
+}
+
+func TestSchema_Validate_OutOfOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+
+	mockScripter := schema_mock.NewMockScripter(ctrl)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(store.SchemaScriptCollection{
+		store.NewSchemaScriptSuccess("002.sql", ""),
+	}, nil)
+
+	s := newSchema(t, mockDB, schema.WithScripter(mockScripter))
+
+	validation, err := s.Validate("./testdata/unit")
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	actual := validation.Scripts(schema.ProblemOutOfOrder)
+	if len(actual) != 1 || actual[0] != "001.sql" {
+		t.Errorf("Expected 001.sql to be reported as out of order but got %v", actual)
+	}
+}
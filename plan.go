@@ -0,0 +1,179 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"github.com/rebel-l/schema/sqlfile"
+)
+
+const (
+	// DirectionUp marks a step which applies a script.
+	DirectionUp = sqlfile.CommandUpgrade
+
+	// DirectionDown marks a step which reverts a script.
+	DirectionDown = sqlfile.CommandDowngrade
+)
+
+// Step represents a single script execution of a Plan.
+type Step struct {
+	Direction string
+	File      string
+
+	// SQL contains the statements as they are executed, each followed by a semicolon.
+	SQL string
+}
+
+// Plan represents the ordered steps a command would execute against the database.
+type Plan []*Step
+
+// Len returns number of steps in the plan.
+func (p Plan) Len() int {
+	return len(p)
+}
+
+// Print writes a human readable representation of the plan to w.
+func (p Plan) Print(w io.Writer) error {
+	_, err := io.WriteString(w, p.String())
+
+	return err
+}
+
+// String returns a human readable representation of the plan.
+func (p Plan) String() string {
+	if len(p) == 0 {
+		return "nothing to do\n"
+	}
+
+	var b strings.Builder
+
+	for i, step := range p {
+		_, _ = fmt.Fprintf(&b, "%d. %s %s\n", i+1, step.Direction, step.File)
+
+		if sql := strings.TrimSpace(step.SQL); sql != "" {
+			_, _ = fmt.Fprintf(&b, "%s\n", sql)
+		}
+	}
+
+	return b.String()
+}
+
+// PlanUpgrade returns the steps Upgrade() would execute without touching the database. Like Upgrade() it fails with
+// ErrChecksumMismatch if applied scripts were modified and with ErrOutOfOrder depending on the out of order policy.
+// A path to the sql scripts needs to be provided. It considers only files with ending ".sql", sub folders are ignored
+// unless WithRecursiveScan() is used. Several paths can be separated by os.PathListSeparator, see Upgrade().
+func (s *Schema) PlanUpgrade(path string) (Plan, error) {
+	return s.planUpgrade(dirSource(path))
+}
+
+// PlanUpgradeFS does the same as PlanUpgrade but takes the scripts from the given file system, see UpgradeFS().
+func (s *Schema) PlanUpgradeFS(fsys fs.FS) (Plan, error) {
+	return s.planUpgrade(fsSource(fsys))
+}
+
+func (s *Schema) planUpgrade(src source) (Plan, error) {
+	executedScripts, err := s.executedScripts(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	scripts, err := src.scan(s.migrations, s.scanOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	relativeNames(scripts, executedScripts)
+
+	if err = s.checkModifications(scripts, executedScripts); err != nil {
+		return nil, err
+	}
+
+	if err = s.checkOutOfOrder(scripts, executedScripts); err != nil {
+		return nil, err
+	}
+
+	plan := Plan{}
+
+	for _, sc := range scripts {
+		pending, err := sc.pending(executedScripts)
+		if err != nil {
+			return nil, err
+		}
+
+		if !pending {
+			continue
+		}
+
+		step, err := s.newStep(DirectionUp, sc)
+		if err != nil {
+			return nil, err
+		}
+
+		plan = append(plan, step)
+	}
+
+	return plan, nil
+}
+
+// PlanRevertN returns the steps RevertN() would execute without touching the database.
+// The parameters are the same as for RevertN().
+func (s *Schema) PlanRevertN(path string, numOfScripts int) (Plan, error) {
+	return s.planRevertN(dirSource(path), numOfScripts)
+}
+
+// PlanRevertNFS does the same as PlanRevertN but takes the scripts from the given file system, see UpgradeFS().
+func (s *Schema) PlanRevertNFS(fsys fs.FS, numOfScripts int) (Plan, error) {
+	return s.planRevertN(fsSource(fsys), numOfScripts)
+}
+
+func (s *Schema) planRevertN(src source, numOfScripts int) (Plan, error) {
+	executedScripts, err := s.executedScripts(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	scripts, err := src.scanReverse(s.migrations, s.scanOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	relativeNames(scripts, executedScripts)
+
+	plan := Plan{}
+
+	for _, sc := range scripts {
+		if !executedScripts.ScriptExecuted(sc.name) || sc.repeatable && numOfScripts > 0 {
+			continue
+		}
+
+		step, err := s.newStep(DirectionDown, sc)
+		if err != nil {
+			return nil, err
+		}
+
+		plan = append(plan, step)
+
+		if numOfScripts > 0 && len(plan) >= numOfScripts {
+			break
+		}
+	}
+
+	return plan, nil
+}
+
+// newStep returns the step for the script containing the statements as they are executed for the dialect.
+func (s *Schema) newStep(direction string, sc *script) (*Step, error) {
+	sql, err := sc.read(direction, sqlfile.SplitOptions{BackslashEscapes: s.dialect.BackslashEscapes()})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Step{
+		Direction: direction,
+		File:      sc.name,
+		SQL:       sql,
+	}, nil
+}
@@ -0,0 +1,319 @@
+package schema_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/rebel-l/schema"
+	"github.com/rebel-l/schema/dialect"
+	"github.com/rebel-l/schema/mocks/schema_mock"
+	"github.com/rebel-l/schema/store"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestSchema_PlanUpgrade_Happy(t *testing.T) {
+	testCases := []struct {
+		name     string
+		dbExists bool
+		executed store.SchemaScriptCollection
+		expected []string
+	}{
+		{
+			name:     "new database",
+			expected: []string{"001.sql", "002.sql"},
+		},
+		{
+			name:     "one script applied",
+			dbExists: true,
+			executed: store.SchemaScriptCollection{
+				&store.SchemaScript{ScriptName: "001.sql", Status: store.StatusSuccess},
+			},
+			expected: []string{"002.sql"},
+		},
+		{
+			name:     "failed script is planned again",
+			dbExists: true,
+			executed: store.SchemaScriptCollection{
+				&store.SchemaScript{ScriptName: "001.sql", Status: store.StatusSuccess},
+				&store.SchemaScript{ScriptName: "002.sql", Status: store.StatusError},
+			},
+			expected: []string{"002.sql"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		dbExists := testCase.dbExists
+		executed := testCase.executed
+		expected := testCase.expected
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDB := getMockDB(ctrl, false)
+			mockScripter := schema_mock.NewMockScripter(ctrl)
+
+			if dbExists {
+				mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+				mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(executed, nil)
+			} else {
+				mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(errors.New("no table")) // nolint: goerr113
+				mockScripter.EXPECT().GetAllContext(gomock.Any()).Times(0)
+			}
+
+			mockApplier := schema_mock.NewMockApplier(ctrl)
+			mockApplier.EXPECT().Init().Times(0)
+			mockApplier.EXPECT().ApplyScriptFSContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			mockScripter.EXPECT().AddContext(gomock.Any(), gomock.Any()).Times(0)
+
+			s := newSchema(t, mockDB, schema.WithApplier(mockApplier), schema.WithScripter(mockScripter))
+
+			plan, err := s.PlanUpgrade("./testdata/unit")
+			if err != nil {
+				t.Fatalf("Expected no error but got %s", err)
+			}
+
+			checkPlan(t, expected, schema.DirectionUp, plan)
+		})
+	}
+}
+
+func TestSchema_PlanUpgrade_Unhappy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+
+	mockScripter := schema_mock.NewMockScripter(ctrl)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(nil, errors.New("failed")) // nolint: goerr113
+
+	s := newSchema(t, mockDB, schema.WithScripter(mockScripter))
+
+	if _, err := s.PlanUpgrade("./testdata/unit"); err == nil {
+		t.Error("Expected error is returned on failed database operation")
+	}
+}
+
+func TestSchema_PlanUpgrade_Unhappy_Checks(t *testing.T) {
+	testCases := []struct {
+		name     string
+		executed store.SchemaScriptCollection
+		opts     []schema.Option
+		expected error
+	}{
+		{
+			name: "modified script",
+			executed: store.SchemaScriptCollection{
+				&store.SchemaScript{ScriptName: "001.sql", Status: store.StatusSuccess, Checksum: "modified"},
+			},
+			expected: schema.ErrChecksumMismatch,
+		},
+		{
+			name: "out of order",
+			executed: store.SchemaScriptCollection{
+				store.NewSchemaScriptSuccess("002.sql", ""),
+			},
+			opts:     []schema.Option{schema.WithOutOfOrder(schema.OutOfOrderReject)},
+			expected: schema.ErrOutOfOrder,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDB := getMockDB(ctrl, false)
+			mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+
+			mockScripter := schema_mock.NewMockScripter(ctrl)
+			mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(testCase.executed, nil)
+
+			s := newSchema(t, mockDB, append(testCase.opts, schema.WithScripter(mockScripter))...)
+
+			if _, err := s.PlanUpgrade("./testdata/unit"); !errors.Is(err, testCase.expected) {
+				t.Errorf("Expected error %s but got %v", testCase.expected, err)
+			}
+		})
+	}
+}
+
+func TestSchema_PlanRevertN_Happy(t *testing.T) {
+	executed := store.SchemaScriptCollection{
+		&store.SchemaScript{ScriptName: "001.sql", Status: store.StatusSuccess},
+		&store.SchemaScript{ScriptName: "002.sql", Status: store.StatusSuccess},
+	}
+
+	testCases := []struct {
+		name         string
+		numOfScripts int
+		expected     []string
+	}{
+		{
+			name:         "last",
+			numOfScripts: 1,
+			expected:     []string{"002.sql"},
+		},
+		{
+			name:         "all",
+			numOfScripts: -1,
+			expected:     []string{"002.sql", "001.sql"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		numOfScripts := testCase.numOfScripts
+		expected := testCase.expected
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDB := getMockDB(ctrl, false)
+			mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+
+			mockScripter := schema_mock.NewMockScripter(ctrl)
+			mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(executed, nil)
+			mockScripter.EXPECT().RemoveContext(gomock.Any(), gomock.Any()).Times(0)
+
+			mockApplier := schema_mock.NewMockApplier(ctrl)
+			mockApplier.EXPECT().RevertScriptFSContext(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+			s := newSchema(t, mockDB, schema.WithApplier(mockApplier), schema.WithScripter(mockScripter))
+
+			plan, err := s.PlanRevertN("./testdata/unit", numOfScripts)
+			if err != nil {
+				t.Fatalf("Expected no error but got %s", err)
+			}
+
+			checkPlan(t, expected, schema.DirectionDown, plan)
+		})
+	}
+}
+
+func TestSchema_PlanRevertN_NewDatabase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(errors.New("no table")) // nolint: goerr113
+
+	mockScripter := schema_mock.NewMockScripter(ctrl)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Times(0)
+
+	s := newSchema(t, mockDB, schema.WithScripter(mockScripter))
+
+	plan, err := s.PlanRevertN("./testdata/unit", -1)
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	checkPlan(t, nil, schema.DirectionDown, plan)
+}
+
+func TestSchema_PlanUpgradeFS_Statements(t *testing.T) {
+	testCases := []struct {
+		name     string
+		dialect  dialect.Dialect
+		content  string
+		expected string
+	}{
+		{
+			name:    "comment lines and multi-line literals",
+			dialect: dialect.SQLite{},
+			content: "-- up\n-- create users\nCREATE TABLE users (\n  name TEXT DEFAULT 'a\n\n  b'\n);\n\n" +
+				"INSERT INTO users VALUES ('x;y');\n",
+			expected: "CREATE TABLE users (\n  name TEXT DEFAULT 'a\n\n  b'\n);\nINSERT INTO users VALUES ('x;y');",
+		},
+		{
+			name:     "backslash escapes of the dialect",
+			dialect:  dialect.MySQL{},
+			content:  "-- up\nINSERT INTO users VALUES ('It\\'s;');\nSELECT 1;\n",
+			expected: "INSERT INTO users VALUES ('It\\'s;');\nSELECT 1;",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDB := getMockDB(ctrl, false)
+			mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(errors.New("no table")) // nolint: goerr113
+
+			s := newSchema(t, mockDB, schema.WithDialect(testCase.dialect))
+
+			plan, err := s.PlanUpgradeFS(fstest.MapFS{"001.sql": {Data: []byte(testCase.content)}})
+			if err != nil {
+				t.Fatalf("Expected no error but got %s", err)
+			}
+
+			if plan.Len() != 1 || plan[0].SQL != testCase.expected {
+				t.Errorf("Expected the statements as executed '%s' but got %v", testCase.expected, plan)
+			}
+		})
+	}
+}
+
+func TestPlan_Print(t *testing.T) {
+	testCases := []struct {
+		name     string
+		plan     schema.Plan
+		expected string
+	}{
+		{
+			name:     "empty",
+			plan:     schema.Plan{},
+			expected: "nothing to do\n",
+		},
+		{
+			name: "steps",
+			plan: schema.Plan{
+				&schema.Step{Direction: schema.DirectionUp, File: "001.sql", SQL: "CREATE TABLE a(id INTEGER);"},
+				&schema.Step{Direction: schema.DirectionUp, File: "002.sql"},
+			},
+			expected: "1. up 001.sql\nCREATE TABLE a(id INTEGER);\n2. up 002.sql\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		plan := testCase.plan
+		expected := testCase.expected
+		t.Run(testCase.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := plan.Print(&buf); err != nil {
+				t.Fatalf("Expected no error but got %s", err)
+			}
+
+			if buf.String() != expected {
+				t.Errorf("Expected output '%s' but got '%s'", expected, buf.String())
+			}
+		})
+	}
+}
+
+func checkPlan(t *testing.T, expected []string, direction string, plan schema.Plan) {
+	t.Helper()
+
+	if len(expected) != plan.Len() {
+		t.Fatalf("Expected %d steps but got %d", len(expected), plan.Len())
+	}
+
+	for i, f := range expected {
+		if plan[i].File != f {
+			t.Errorf("Expected step %d to be file %s but got %s", i, f, plan[i].File)
+		}
+
+		if plan[i].Direction != direction {
+			t.Errorf("Expected direction %s but got %s", direction, plan[i].Direction)
+		}
+
+		if plan[i].SQL == "" {
+			t.Errorf("Expected SQL for step %d", i)
+		}
+	}
+}
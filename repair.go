@@ -0,0 +1,159 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+
+	"github.com/rebel-l/schema/store"
+)
+
+// ErrNoScripts is returned by Repair() if no scripts were found, as it would mark all applied scripts as orphaned.
+var ErrNoScripts = errors.New("no scripts found, check the path")
+
+const (
+	// RepairRemoved marks a log entry of a failed script execution which was removed.
+	RepairRemoved = "removed"
+
+	// RepairChecksum marks an applied script whose checksum was updated to its current content.
+	RepairChecksum = "checksum"
+
+	// RepairOrphaned marks an applied script which doesn't exist anymore and was marked as orphaned.
+	RepairOrphaned = "orphaned"
+)
+
+// Change describes a single change done by Repair().
+type Change struct {
+	Script string
+	Action string
+}
+
+// RepairReport represents the changes done by Repair().
+type RepairReport []*Change
+
+// Len returns number of changes.
+func (r RepairReport) Len() int {
+	return len(r)
+}
+
+// Scripts returns the names of the scripts having a change of the given action.
+func (r RepairReport) Scripts(action string) []string {
+	scripts := make([]string, 0)
+
+	for _, c := range r {
+		if c.Action == action {
+			scripts = append(scripts, c.Script)
+		}
+	}
+
+	return scripts
+}
+
+// Repair cleans up the log of script executions: it removes the entries of failed executions, updates the checksums
+// of applied scripts which were modified intentionally and marks applied scripts missing in path as orphaned.
+// An orphaned script still counts as applied, so it is not applied again if it appears in path later, but it isn't
+// reported as missing by Validate() and Status() anymore. If path contains no scripts, ErrNoScripts is returned.
+// It returns the changes done, see Validate() to check the problems before.
+func (s *Schema) Repair(path string) (RepairReport, error) {
+	return s.repairWithLock(dirSource(path))
+}
+
+// RepairFS does the same as Repair but takes the scripts from the given file system, see UpgradeFS().
+func (s *Schema) RepairFS(fsys fs.FS) (RepairReport, error) {
+	return s.repairWithLock(fsSource(fsys))
+}
+
+func (s *Schema) repairWithLock(src source) (report RepairReport, err error) {
+	err = s.withLock(context.Background(), func(ctx context.Context) error {
+		report, err = s.repair(ctx, src)
+		return err
+	})
+
+	return report, err
+}
+
+func (s *Schema) repair(ctx context.Context, src source) (RepairReport, error) {
+	report := RepairReport{}
+
+	if !checkDatabaseExists(s.db, s.dialect, s.tables) {
+		return report, nil
+	}
+
+	scripts, err := src.scan(s.migrations, s.scanOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(scripts) == 0 {
+		return nil, ErrNoScripts
+	}
+
+	failed, err := s.scripter.GetFailedContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	executedScripts, err := s.scripter.GetAllContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range failed {
+		if err = s.scripter.RemoveByIDContext(ctx, e.ID); err != nil {
+			return report, err
+		}
+
+		report = append(report, &Change{Script: e.ScriptName, Action: RepairRemoved})
+	}
+
+	if err = s.migrateScriptNames(ctx, scripts, executedScripts); err != nil {
+		return report, err
+	}
+
+	known := make(map[string]bool)
+
+	for _, sc := range scripts {
+		known[sc.name] = true
+
+		applied := executedScripts.LastSuccess(sc.name)
+		if applied == nil || sc.repeatable {
+			continue
+		}
+
+		checksum, err := sc.checksum()
+		if err != nil {
+			return report, err
+		}
+
+		if checksum == applied.Checksum {
+			continue
+		}
+
+		applied.Checksum = checksum
+		if err = s.scripter.UpdateContext(ctx, applied); err != nil {
+			return report, err
+		}
+
+		report = append(report, &Change{Script: sc.name, Action: RepairChecksum})
+	}
+
+	orphaned := make(map[string]bool)
+
+	for _, e := range executedScripts {
+		if !e.Applied() || e.Orphaned() || known[e.ScriptName] {
+			continue
+		}
+
+		e.Status = store.StatusOrphaned
+		if err = s.scripter.UpdateContext(ctx, e); err != nil {
+			return report, err
+		}
+
+		if !orphaned[e.ScriptName] {
+			orphaned[e.ScriptName] = true
+			report = append(report, &Change{Script: e.ScriptName, Action: RepairOrphaned})
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,198 @@
+package schema_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/rebel-l/go-utils/osutils"
+	"github.com/rebel-l/schema"
+	"github.com/rebel-l/schema/mocks/schema_mock"
+	"github.com/rebel-l/schema/store"
+	"github.com/rebel-l/schema/utils/testdb"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestSchema_Repair_Unhappy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+
+	mockScripter := schema_mock.NewMockScripter(ctrl)
+	mockScripter.EXPECT().GetFailedContext(gomock.Any()).Return(nil, errors.New("select failed")) // nolint: goerr113
+	mockScripter.EXPECT().RemoveByIDContext(gomock.Any(), gomock.Any()).Times(0)
+	mockScripter.EXPECT().UpdateContext(gomock.Any(), gomock.Any()).Times(0)
+
+	s := newSchema(t, mockDB, schema.WithScripter(mockScripter))
+
+	if _, err := s.Repair("./testdata/unit"); err == nil {
+		t.Error("Expected error if failed executions can't be loaded")
+	}
+}
+
+func TestSchema_Repair_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_repair.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	dir := t.TempDir()
+	for _, f := range []string{"001.sql", "002.sql"} {
+		if err = osutils.CopyFile("./testdata/upgrade/happy/"+f, dir+"/"+f); err != nil {
+			t.Fatalf("failed to copy file: %s", err)
+		}
+	}
+
+	s := newSchema(t, db)
+	if err = s.Upgrade(dir, ""); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	broken := "-- up\nCREATE TABLE not_existing.broken(id INTEGER);\n-- down\nDROP TABLE IF EXISTS broken;\n"
+	if err = os.WriteFile(dir+"/003.sql", []byte(broken), 0600); err != nil {
+		t.Fatalf("failed to create file: %s", err)
+	}
+
+	if err = s.Upgrade(dir, ""); err == nil {
+		t.Fatal("Expected error on broken script")
+	}
+
+	modified := "-- up\nCREATE TABLE IF NOT EXISTS modified(id INTEGER);\n-- down\nDROP TABLE IF EXISTS modified;\n"
+	if err = os.WriteFile(dir+"/001.sql", []byte(modified), 0600); err != nil {
+		t.Fatalf("failed to modify file: %s", err)
+	}
+
+	if err = os.Remove(dir + "/002.sql"); err != nil {
+		t.Fatalf("failed to remove file: %s", err)
+	}
+
+	report, err := s.Repair(dir)
+	if err != nil {
+		t.Fatalf("Expected no error on repair but got %s", err)
+	}
+
+	expected := map[string][]string{
+		schema.RepairRemoved:  {"003.sql"},
+		schema.RepairChecksum: {"001.sql"},
+		schema.RepairOrphaned: {"002.sql"},
+	}
+
+	for action, scripts := range expected {
+		if actual := report.Scripts(action); !reflect.DeepEqual(scripts, actual) {
+			t.Errorf("Expected %v for action %s but got %v", scripts, action, actual)
+		}
+	}
+
+	failed, err := store.NewSchemaScriptMapper(db).GetFailed()
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if failed.Len() != 0 {
+		t.Errorf("Expected no failed entries after repair but got %d", failed.Len())
+	}
+
+	validation, err := s.Validate(dir)
+	if err != nil {
+		t.Fatalf("Expected no error on validation but got %s", err)
+	}
+
+	if validation.Len() != 1 || validation.Scripts(schema.ProblemPending)[0] != "003.sql" {
+		t.Errorf("Expected only the broken script to be pending after repair but got %d problems", validation.Len())
+	}
+
+	data, err := store.NewSchemaScriptMapper(db).GetAll()
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	for _, v := range data {
+		if v.ScriptName == "002.sql" && v.Status != store.StatusOrphaned {
+			t.Errorf("Expected status %s for removed script but got %s", store.StatusOrphaned, v.Status)
+		}
+	}
+
+	report, err = s.Repair(dir)
+	if err != nil {
+		t.Fatalf("Expected no error on second repair but got %s", err)
+	}
+
+	if report.Len() != 0 {
+		t.Errorf("Expected nothing to repair twice but got %d changes", report.Len())
+	}
+}
+
+func TestSchema_Repair_Integration_WrongPath(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_repair_wrong_path.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	dir := t.TempDir()
+	for _, f := range []string{"001.sql", "002.sql"} {
+		if err = osutils.CopyFile("./testdata/upgrade/happy/"+f, dir+"/"+f); err != nil {
+			t.Fatalf("failed to copy file: %s", err)
+		}
+	}
+
+	s := newSchema(t, db)
+	if err = s.Upgrade(dir, ""); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if _, err = s.Repair(t.TempDir()); !errors.Is(err, schema.ErrNoScripts) {
+		t.Errorf("Expected error %s on empty path but got %v", schema.ErrNoScripts, err)
+	}
+
+	wrong := t.TempDir()
+	if err = osutils.CopyFile("./testdata/upgrade/step1/001.sql", wrong+"/100_other.sql"); err != nil {
+		t.Fatalf("failed to copy file: %s", err)
+	}
+
+	report, err := s.Repair(wrong)
+	if err != nil {
+		t.Fatalf("Expected no error on repair but got %s", err)
+	}
+
+	if actual := report.Scripts(schema.RepairOrphaned); !reflect.DeepEqual([]string{"001.sql", "002.sql"}, actual) {
+		t.Errorf("Expected all applied scripts to be orphaned but got %v", actual)
+	}
+
+	if err = s.Upgrade(dir, ""); err != nil {
+		t.Fatalf("Expected no error on upgrade after repair but got %s", err)
+	}
+
+	data, err := store.NewSchemaScriptMapper(db).GetAll()
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if data.Len() != 2 {
+		t.Errorf("Expected orphaned scripts not to be executed again but got %d entries", data.Len())
+	}
+
+	validation, err := s.Validate(dir)
+	if err != nil {
+		t.Fatalf("Expected no error on validation but got %s", err)
+	}
+
+	if validation.Len() != 0 {
+		t.Errorf("Expected no problems for orphaned scripts back in path but got %d", validation.Len())
+	}
+}
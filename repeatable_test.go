@@ -0,0 +1,118 @@
+package schema_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rebel-l/go-utils/osutils"
+	"github.com/rebel-l/schema"
+	"github.com/rebel-l/schema/store"
+	"github.com/rebel-l/schema/utils/testdb"
+)
+
+func countScriptRuns(t *testing.T, db store.DatabaseConnector, scriptName string) int {
+	t.Helper()
+
+	data, err := store.NewSchemaScriptMapper(db).GetAll()
+	if err != nil {
+		t.Fatalf("not able get rows from table: %s", err)
+	}
+
+	counter := 0
+
+	for _, v := range data {
+		if v.ScriptName == scriptName && v.Status == store.StatusSuccess {
+			counter++
+		}
+	}
+
+	return counter
+}
+
+func TestSchema_Upgrade_Integration_Repeatable(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_repeatable.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	dir := t.TempDir()
+	for _, f := range []string{"001_items.sql", "R__items_view.sql"} {
+		if err = osutils.CopyFile("./testdata/repeatable/"+f, dir+"/"+f); err != nil {
+			t.Fatalf("failed to copy file: %s", err)
+		}
+	}
+
+	view := dir + "/R__items_view.sql"
+
+	s := newSchema(t, db)
+
+	// first run applies both, second run nothing
+	for i := 0; i < 2; i++ {
+		if err = s.Upgrade(dir, ""); err != nil {
+			t.Fatalf("Expected no error but got %s", err)
+		}
+
+		if actual := countScriptRuns(t, db, "R__items_view.sql"); actual != 1 {
+			t.Fatalf("Expected repeatable script to be applied once but got %d", actual)
+		}
+	}
+
+	content := "-- up\nDROP VIEW IF EXISTS items_view;\nCREATE VIEW items_view AS SELECT name, price FROM items;\n" +
+		"-- down\nDROP VIEW IF EXISTS items_view;\n"
+	if err = os.WriteFile(view, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to modify file: %s", err)
+	}
+
+	validation, err := s.Validate(dir)
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if validation.Err() != nil || len(validation.Scripts(schema.ProblemPending)) != 1 {
+		t.Errorf("Expected modified repeatable script to be pending but got %v", validation.Err())
+	}
+
+	report, err := s.Status(dir)
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if report.Count(schema.StatePending) != 1 {
+		t.Errorf("Expected modified repeatable script to have state %s", schema.StatePending)
+	}
+
+	if err = s.Upgrade(dir, ""); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if actual := countScriptRuns(t, db, "R__items_view.sql"); actual != 2 {
+		t.Errorf("Expected repeatable script to be applied twice but got %d", actual)
+	}
+
+	var prices []uint32
+	if err = db.Select(&prices, "SELECT price FROM items_view;"); err != nil {
+		t.Errorf("Expected that the view was changed but got %s", err)
+	}
+
+	if err = s.RevertLast(dir); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if countScriptRuns(t, db, "001_items.sql") != 0 || countScriptRuns(t, db, "R__items_view.sql") != 2 {
+		t.Error("Expected that only the versioned script was reverted")
+	}
+
+	if err = s.RevertAll(dir); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if actual := countScriptRuns(t, db, "R__items_view.sql"); actual != 0 {
+		t.Errorf("Expected that repeatable script was reverted but got %d runs", actual)
+	}
+}
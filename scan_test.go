@@ -0,0 +1,350 @@
+package schema_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/rebel-l/go-utils/osutils"
+
+	"github.com/rebel-l/schema"
+	"github.com/rebel-l/schema/mocks/schema_mock"
+	"github.com/rebel-l/schema/sqlfile"
+	"github.com/rebel-l/schema/store"
+	"github.com/rebel-l/schema/utils/testdb"
+
+	"github.com/golang/mock/gomock"
+)
+
+func appliedScripts(t *testing.T, db store.DatabaseConnector) []string {
+	t.Helper()
+
+	data, err := store.NewSchemaScriptMapper(db).GetAll()
+	if err != nil {
+		t.Fatalf("not able get rows from table: %s", err)
+	}
+
+	names := make([]string, 0, len(data))
+	for _, v := range data {
+		names = append(names, v.ScriptName)
+	}
+
+	return names
+}
+
+func TestSchema_Upgrade_Integration_Recursive(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_recursive.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	s := newSchema(t, db, schema.WithRecursiveScan(), schema.WithExclude("legacy"))
+
+	if err = s.Upgrade("./testdata/multi", ""); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	expected := []string{
+		"users/001_users.sql",
+		"billing/002_invoices.sql",
+		"users/003_user_email.sql",
+	}
+
+	if actual := appliedScripts(t, db); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected applied scripts %v but got %v", expected, actual)
+	}
+
+	if err = s.RevertAll("./testdata/multi"); err != nil {
+		t.Fatalf("Expected no error on revert but got %s", err)
+	}
+
+	if actual := appliedScripts(t, db); len(actual) != 0 {
+		t.Errorf("Expected all scripts reverted but got %v", actual)
+	}
+}
+
+func TestSchema_Upgrade_Integration_MultiplePaths(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_multiple_paths.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	s := newSchema(t, db)
+	paths := "./testdata/multi/billing" + string(os.PathListSeparator) + "./testdata/multi/users"
+
+	if err = s.Upgrade(paths, ""); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	expected := []string{
+		"001_users.sql",
+		"002_invoices.sql",
+		"003_user_email.sql",
+	}
+
+	if actual := appliedScripts(t, db); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected applied scripts %v but got %v", expected, actual)
+	}
+}
+
+func TestSchema_Upgrade_Integration_DuplicateVersion(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_duplicate_version.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	s := newSchema(t, db, schema.WithRecursiveScan())
+
+	if err = s.Upgrade("./testdata/multi_duplicate", ""); !errors.Is(err, sqlfile.ErrDuplicateVersion) {
+		t.Errorf("Expected error %s but got %v", sqlfile.ErrDuplicateVersion, err)
+	}
+
+	if actual := appliedScripts(t, db); len(actual) != 0 {
+		t.Errorf("Expected that nothing is applied but got %v", actual)
+	}
+}
+
+func TestSchema_Upgrade_Integration_NaturalOrder(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_natural_order.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	s := newSchema(t, db)
+	if err = s.Upgrade("./testdata/natural", ""); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	expected := []string{"9_create.sql", "10_alter.sql"}
+	if actual := appliedScripts(t, db); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected applied scripts %v but got %v", expected, actual)
+	}
+
+	if err = s.RevertLast("./testdata/natural"); err != nil {
+		t.Fatalf("Expected no error on revert but got %s", err)
+	}
+
+	if actual := appliedScripts(t, db); !reflect.DeepEqual(expected[:1], actual) {
+		t.Errorf("Expected applied scripts %v after revert but got %v", expected[:1], actual)
+	}
+}
+
+func TestSchema_Upgrade_RelativeNames(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+
+	mockApplier := schema_mock.NewMockApplier(ctrl)
+	mockApplier.EXPECT().
+		ApplyScriptFSContext(gomock.Any(), gomock.Any(), "002.sql", gomock.Any()).Times(1).
+		DoAndReturn(runCallbacks(mockDB))
+
+	executed := store.SchemaScriptCollection{
+		&store.SchemaScript{ScriptName: "/app/db/migrations/001.sql", Status: store.StatusSuccess},
+	}
+
+	mockScripter := schema_mock.NewMockScripter(ctrl)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(executed, nil)
+	mockScripter.EXPECT().RenameContext(gomock.Any(), "/app/db/migrations/001.sql", "001.sql").Times(1).Return(nil)
+	mockScripter.EXPECT().AddWithContext(gomock.Any(), mockDB, gomock.Any()).Times(1).Return(nil)
+
+	s := newSchema(t, mockDB, schema.WithApplier(mockApplier), schema.WithScripter(mockScripter))
+
+	if err := s.Upgrade("./testdata/unit", ""); err != nil {
+		t.Errorf("Expected no errors but got %s", err)
+	}
+}
+
+func TestSchema_Upgrade_Unhappy_RenameError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+
+	mockApplier := schema_mock.NewMockApplier(ctrl)
+	mockApplier.EXPECT().ApplyScriptFSContext(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	executed := store.SchemaScriptCollection{
+		&store.SchemaScript{ScriptName: "./db/001.sql", Status: store.StatusSuccess},
+	}
+
+	failure := errors.New("failed") // nolint: goerr113
+
+	mockScripter := schema_mock.NewMockScripter(ctrl)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(executed, nil)
+	mockScripter.EXPECT().RenameContext(gomock.Any(), "./db/001.sql", "001.sql").Return(failure)
+
+	s := newSchema(t, mockDB, schema.WithApplier(mockApplier), schema.WithScripter(mockScripter))
+
+	if err := s.Upgrade("./testdata/unit", ""); !errors.Is(err, failure) {
+		t.Errorf("Expected error %s but got %v", failure, err)
+	}
+}
+
+func TestSchema_Upgrade_Integration_RelativeNames(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_relative_names.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	s := newSchema(t, db)
+	if err = s.Upgrade("./testdata/upgrade/happy", ""); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	// entries recorded by former versions including the path
+	if _, err = db.Exec("UPDATE schema_script SET script_name = './db/migrations/' || script_name;"); err != nil {
+		t.Fatalf("failed to prepare entries: %s", err)
+	}
+
+	validation, err := s.Validate("./testdata/upgrade/happy")
+	if err != nil {
+		t.Fatalf("Expected no error on validation but got %s", err)
+	}
+
+	if validation.Len() != 0 {
+		t.Errorf("Expected no problems but got %v", validation.Scripts(schema.ProblemMissing))
+	}
+
+	expected := []string{"./db/migrations/001.sql", "./db/migrations/002.sql"}
+	if actual := appliedScripts(t, db); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected validation not to change entries %v but got %v", expected, actual)
+	}
+
+	// same scripts executed from another path
+	dir := t.TempDir()
+	for _, f := range []string{"001.sql", "002.sql"} {
+		if err = osutils.CopyFile("./testdata/upgrade/happy/"+f, dir+"/"+f); err != nil {
+			t.Fatalf("failed to copy file: %s", err)
+		}
+	}
+
+	if err = s.Upgrade(dir, ""); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	expected = []string{"001.sql", "002.sql"}
+	if actual := appliedScripts(t, db); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected entries %v without path and nothing applied again but got %v", expected, actual)
+	}
+
+	if err = s.RevertAll("./testdata/upgrade/happy"); err != nil {
+		t.Fatalf("Expected no error on revert but got %s", err)
+	}
+
+	if actual := appliedScripts(t, db); len(actual) != 0 {
+		t.Errorf("Expected all scripts reverted but got %v", actual)
+	}
+}
+
+func TestSchema_Upgrade_Integration_MovedScript(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_moved_script.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	dir := t.TempDir()
+	for _, sub := range []string{"/users", "/billing"} {
+		if err = os.Mkdir(dir+sub, 0700); err != nil {
+			t.Fatalf("failed to create folder: %s", err)
+		}
+	}
+
+	for _, f := range []string{"001.sql", "002.sql"} {
+		if err = osutils.CopyFile("./testdata/upgrade/happy/"+f, dir+"/users/"+f); err != nil {
+			t.Fatalf("failed to copy file: %s", err)
+		}
+	}
+
+	s := newSchema(t, db, schema.WithRecursiveScan())
+	if err = s.Upgrade(dir, ""); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if err = os.Rename(dir+"/users/002.sql", dir+"/billing/002.sql"); err != nil {
+		t.Fatalf("failed to move file: %s", err)
+	}
+
+	validation, err := s.Validate(dir)
+	if err != nil {
+		t.Fatalf("Expected no error on validation but got %s", err)
+	}
+
+	if validation.Len() != 0 {
+		t.Errorf("Expected no problems for moved script but got %d", validation.Len())
+	}
+
+	if err = s.Upgrade(dir, ""); err != nil {
+		t.Fatalf("Expected moved script not to be applied again but got %s", err)
+	}
+
+	expected := []string{"users/001.sql", "billing/002.sql"}
+	if actual := appliedScripts(t, db); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected entries %v renamed to the new folder but got %v", expected, actual)
+	}
+}
+
+func TestSchema_Upgrade_Integration_DuplicateName(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_duplicate_name.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	first, second := t.TempDir(), t.TempDir()
+	for _, dir := range []string{first, second} {
+		if err = osutils.CopyFile("./testdata/repeatable/R__items_view.sql", dir+"/R__items_view.sql"); err != nil {
+			t.Fatalf("failed to copy file: %s", err)
+		}
+	}
+
+	s := newSchema(t, db)
+	if err = s.Upgrade(first+string(os.PathListSeparator)+second, ""); !errors.Is(err, sqlfile.ErrScanFiles) {
+		t.Errorf("Expected error %s but got %v", sqlfile.ErrScanFiles, err)
+	}
+}
@@ -1,73 +1,315 @@
 // Package schema provides a library to organize and deploy your database schema
 package schema
 
-//go:generate mockgen -destination=mocks/schema_mock/schema_mock.go -package=schema_mock github.com/rebel-l/schema Applier,Scripter
+//go:generate mockgen -destination=mocks/schema_mock/schema_mock.go -package=schema_mock github.com/rebel-l/schema Applier,Locker,Scripter
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"time"
 
-	"github.com/rebel-l/schema/bar"
+	"github.com/rebel-l/schema/dialect"
 	"github.com/rebel-l/schema/initdb"
 	"github.com/rebel-l/schema/sqlfile"
 	"github.com/rebel-l/schema/store"
 
-	"github.com/cheggaaa/pb/v3"
+	"github.com/jmoiron/sqlx"
 )
 
 // Scripter provides methods to manage the access to log of SQL script executions.
 type Scripter interface {
-	Add(entry *store.SchemaScript) error
-	GetAll() (store.SchemaScriptCollection, error)
-	Remove(scriptName string) error
+	AddContext(ctx context.Context, entry *store.SchemaScript) error
+	AddWithContext(ctx context.Context, exec sqlx.Execer, entry *store.SchemaScript) error
+	GetAllContext(ctx context.Context) (store.SchemaScriptCollection, error)
+	GetFailedContext(ctx context.Context) (store.SchemaScriptCollection, error)
+	RemoveContext(ctx context.Context, scriptName string) error
+	RemoveByIDContext(ctx context.Context, id int64) error
+	RemoveWithContext(ctx context.Context, exec sqlx.Execer, scriptName string) error
+	RenameContext(ctx context.Context, oldName string, newName string) error
+	UpdateContext(ctx context.Context, entry *store.SchemaScript) error
 }
 
 // Applier provides methods to apply sql script to database.
 type Applier interface {
-	ApplyScript(fileName string) error
-	RevertScript(fileName string) error
+	ApplyScriptFSContext(ctx context.Context, fsys fs.FS, fileName string, callbacks ...initdb.Callback) error
+	ApplyFuncContext(ctx context.Context, f initdb.Func, callbacks ...initdb.Callback) error
+	RevertScriptFSContext(ctx context.Context, fsys fs.FS, fileName string, callbacks ...initdb.Callback) error
 	Init() error
 	ReInit() error
 }
 
-// Progressor provides methods to steer a progress bar.
-type Progressor interface {
-	Increment() *pb.ProgressBar
-	Finish() *pb.ProgressBar
-}
-
 // Schema provides commands to organize your database schema.
 type Schema struct {
-	Scripter    Scripter
-	Applier     Applier
-	progressBar bool
-	db          store.DatabaseConnector
+	scripter       Scripter
+	applier        Applier
+	locker         Locker
+	skipValidation bool
+	locking        bool
+	advisoryLock   bool
+	lockTimeout    time.Duration
+	lockTTL        time.Duration
+	lockOwner      string
+	timeout        time.Duration
+	scriptTimeout  time.Duration
+	dialect        dialect.Dialect
+	tables         store.Tables
+	migrations     []*migration
+	scanOptions    sqlfile.ScanOptions
+	outOfOrder     OutOfOrderPolicy
+	subscribers    []Subscriber
+	logger         *slog.Logger
+	db             store.DatabaseConnector
+}
+
+// Option configures a Schema, see New().
+type Option func(s *Schema) error
+
+var (
+	// ErrInvalidOption is used if an option passed to New() has an invalid value
+	ErrInvalidOption = errors.New("invalid option")
+)
+
+// New returns a Schema struct configured by the options, e.g. New(db, WithLock(time.Minute)). Without options the SQL
+// dialect is detected from the driver of db and the default implementations of Scripter, Applier and Locker are used.
+// The options are applied in the given order, an invalid one fails with ErrInvalidOption.
+func New(db store.DatabaseConnector, opts ...Option) (Schema, error) {
+	s := Schema{
+		lockTTL:   DefaultLockTTL,
+		lockOwner: newLockOwner(),
+		tables:    store.DefaultTables(),
+		db:        db,
+	}
+
+	for _, opt := range opts {
+		if err := opt(&s); err != nil {
+			return Schema{}, err
+		}
+	}
+
+	if s.dialect == nil {
+		s.dialect = dialect.Detect(db)
+	}
+
+	if s.scripter == nil {
+		scripter := store.NewSchemaScriptMapperWithTables(db, s.dialect, s.tables)
+		if s.logger != nil {
+			scripter.WithLogger(s.logger)
+		}
+
+		s.scripter = scripter
+	}
+
+	if s.applier == nil {
+		applier := initdb.NewWithTables(db, s.dialect, s.tables)
+		if s.logger != nil {
+			applier.WithLogger(s.logger)
+		}
+
+		s.applier = applier
+	}
+
+	if s.locker == nil && s.advisoryLock {
+		locker, err := store.NewSchemaAdvisoryLocker(db, s.dialect, s.tables)
+		if err != nil {
+			return Schema{}, fmt.Errorf("%w: %v", ErrInvalidOption, err)
+		}
+
+		s.locker = locker
+	}
+
+	if s.locker == nil {
+		s.locker = store.NewSchemaLockMapperWithTables(db, s.dialect, s.tables)
+	}
+
+	return s, nil
 }
 
-// New returns a Schema struct.
-func New(db store.DatabaseConnector) Schema {
-	return Schema{
-		Scripter: store.NewSchemaScriptMapper(db),
-		Applier:  initdb.New(db),
-		db:       db,
+// WithDialect sets the SQL dialect of the database. Default is the dialect detected from the driver of the database.
+func WithDialect(d dialect.Dialect) Option {
+	return func(s *Schema) error {
+		if d == nil {
+			return fmt.Errorf("%w: dialect must be provided", ErrInvalidOption)
+		}
+
+		s.dialect = d
+
+		return nil
 	}
 }
 
-// WithProgressBar activate the progress bar.
-func (s *Schema) WithProgressBar() {
-	s.progressBar = true
+// WithTableName sets the name of the table logging the script executions, the default is "schema_script". The lock
+// and meta table are named after it, e.g. "billing_script" results in "billing_script_lock" and
+// "billing_script_meta".
+func WithTableName(name string) Option {
+	return func(s *Schema) error {
+		if name == "" {
+			return fmt.Errorf("%w: table name must be provided", ErrInvalidOption)
+		}
+
+		s.tables = store.NewTables(s.tables.Namespace, name)
+
+		return nil
+	}
+}
+
+// WithNamespace creates and accesses the tables of this package in the given namespace: the schema in PostgreSQL and
+// SQL Server, the database in MySQL or the attached database in SQLite. The namespace must exist already.
+func WithNamespace(namespace string) Option {
+	return func(s *Schema) error {
+		if namespace == "" {
+			return fmt.Errorf("%w: namespace must be provided", ErrInvalidOption)
+		}
+
+		s.tables.Namespace = namespace
+
+		return nil
+	}
+}
+
+// WithScripter replaces the default implementation logging the script executions.
+func WithScripter(scripter Scripter) Option {
+	return func(s *Schema) error {
+		if scripter == nil {
+			return fmt.Errorf("%w: scripter must be provided", ErrInvalidOption)
+		}
+
+		s.scripter = scripter
+
+		return nil
+	}
+}
+
+// WithApplier replaces the default implementation applying the scripts to the database.
+func WithApplier(applier Applier) Option {
+	return func(s *Schema) error {
+		if applier == nil {
+			return fmt.Errorf("%w: applier must be provided", ErrInvalidOption)
+		}
+
+		s.applier = applier
+
+		return nil
+	}
+}
+
+// WithTimeout sets the maximum duration of a whole command like Upgrade(), including waiting for the lock.
+// Zero means no limit.
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *Schema) error {
+		if timeout < 0 {
+			return fmt.Errorf("%w: timeout must not be negative", ErrInvalidOption)
+		}
+
+		s.timeout = timeout
+
+		return nil
+	}
+}
+
+// WithScriptTimeout sets the maximum duration of a single script. A script exceeding it is rolled back (if it runs
+// inside a transaction) and logged with status timeout. Zero means no limit.
+func WithScriptTimeout(timeout time.Duration) Option {
+	return func(s *Schema) error {
+		if timeout < 0 {
+			return fmt.Errorf("%w: script timeout must not be negative", ErrInvalidOption)
+		}
+
+		s.scriptTimeout = timeout
+
+		return nil
+	}
+}
+
+// WithRecursiveScan activates scanning the sub folders of path for scripts. The scripts of all folders are ordered by
+// their file name, see sqlfile.SortFiles().
+func WithRecursiveScan() Option {
+	return func(s *Schema) error {
+		s.scanOptions.Recursive = true
+
+		return nil
+	}
+}
+
+// WithInclude restricts the scripts to the ones matching at least one of the glob patterns, see sqlfile.ScanOptions.
+func WithInclude(patterns ...string) Option {
+	return func(s *Schema) error {
+		if err := checkPatterns(patterns); err != nil {
+			return err
+		}
+
+		s.scanOptions.Include = append(s.scanOptions.Include, patterns...)
+
+		return nil
+	}
+}
+
+// WithExclude skips scripts and sub folders matching one of the glob patterns, see sqlfile.ScanOptions.
+func WithExclude(patterns ...string) Option {
+	return func(s *Schema) error {
+		if err := checkPatterns(patterns); err != nil {
+			return err
+		}
+
+		s.scanOptions.Exclude = append(s.scanOptions.Exclude, patterns...)
+
+		return nil
+	}
+}
+
+func checkPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("%w: pattern %q: %s", ErrInvalidOption, pattern, err)
+		}
+	}
+
+	return nil
 }
 
 // Upgrade applies new scripts to the database or if executed the first time applies all.
-// A path to the sql scripts needs to be provided. It applies only files with ending ".sql", sub folders are ignored.
+// A path to the sql scripts needs to be provided. It applies only files with ending ".sql", sub folders are ignored
+// unless WithRecursiveScan() is used. Several paths can be separated by os.PathListSeparator, e.g. "billing:users".
 // The version of your application can be provided too, use empty string to ignore it.
+// Before anything is applied, it fails with ErrChecksumMismatch if applied scripts were modified afterwards,
+// see Validate() and WithoutValidation().
+// Pending scripts older than the newest applied script are handled as set by WithOutOfOrder().
+// Repeatable scripts (see sqlfile.IsRepeatable()) are applied after all versioned scripts whenever their content
+// changed since their last successful run.
 func (s *Schema) Upgrade(path string, version string) error {
-	if !checkDatabaseExists(s.db) {
-		if err := s.Applier.Init(); err != nil {
-			return err
-		}
+	return s.UpgradeContext(context.Background(), path, version)
+}
+
+// UpgradeContext does the same as Upgrade but stops if the context is done, e.g. on SIGTERM. A script which is
+// interrupted is rolled back if it runs inside a transaction.
+func (s *Schema) UpgradeContext(ctx context.Context, path string, version string) error {
+	return s.withLock(ctx, func(ctx context.Context) error {
+		return s.upgrade(ctx, dirSource(path), version)
+	})
+}
+
+// UpgradeFS does the same as Upgrade but takes the scripts from the root of the given file system, e.g. an embed.FS.
+// The scripts are logged by their name inside the file system.
+func (s *Schema) UpgradeFS(fsys fs.FS, version string) error {
+	return s.UpgradeFSContext(context.Background(), fsys, version)
+}
+
+// UpgradeFSContext does the same as UpgradeFS but stops if the context is done, see UpgradeContext().
+func (s *Schema) UpgradeFSContext(ctx context.Context, fsys fs.FS, version string) error {
+	return s.withLock(ctx, func(ctx context.Context) error {
+		return s.upgrade(ctx, fsSource(fsys), version)
+	})
+}
+
+func (s *Schema) upgrade(ctx context.Context, src source, version string) error {
+	if err := s.init(); err != nil {
+		return err
 	}
 
-	executedScripts, err := s.Scripter.GetAll()
+	executedScripts, err := s.scripter.GetAllContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -75,62 +317,190 @@ func (s *Schema) Upgrade(path string, version string) error {
 	/**
 	1. scan files
 	2. iterate over files in directory
-	2a. check if file is applied
+	2a. check if file is applied, repeatable scripts also if their checksum changed
 	2b. if 2a) is false load each file apply to database
-	2c. store executed script from 2b) to database as success or error
+	2c. store executed script from 2b) to database as success (within the transaction of 2b) or error
+	3. emit the events of the upgrade and each script to the subscribers
 	*/
-	files, err := sqlfile.Scan(path)
+	scripts, err := src.scan(s.migrations, s.scanOptions)
 	if err != nil {
 		return err
 	}
 
-	progressBar := s.startProgressBar(len(files))
+	if err = s.migrateScriptNames(ctx, scripts, executedScripts); err != nil {
+		return err
+	}
 
-	for _, f := range files {
-		progressBar.Increment()
+	if err = s.checkModifications(scripts, executedScripts); err != nil {
+		return err
+	}
 
-		if executedScripts.ScriptExecuted(f) {
-			continue
-		}
+	if err = s.checkOutOfOrder(scripts, executedScripts); err != nil {
+		return err
+	}
 
-		if err = s.Applier.ApplyScript(f); err != nil {
-			msg := fmt.Errorf("failed to execute script %s: %w", f, err)
-			if err := s.Scripter.Add(store.NewSchemaScriptError(f, version, err.Error())); err != nil {
-				msg = fmt.Errorf("original error: %v, following error: %w", msg, err)
-			}
+	s.warnOutOfOrder(scripts, executedScripts, version)
 
-			return msg
-		}
+	applies := make([]*script, 0, len(scripts))
 
-		if err = s.Scripter.Add(store.NewSchemaScriptSuccess(f, version)); err != nil {
+	for _, sc := range scripts {
+		pending, err := sc.pending(executedScripts)
+		if err != nil {
 			return err
 		}
+
+		if pending {
+			applies = append(applies, sc)
+		}
 	}
 
-	progressBar.Finish()
+	return s.run(DirectionUp, version, applies, func(sc *script) error {
+		return s.applyScript(ctx, sc, version)
+	})
+}
+
+// applyScript applies a script or a migration written in Go and logs its execution as success, error or timeout.
+func (s *Schema) applyScript(ctx context.Context, sc *script, version string) error {
+	// don't log an error for a script which wasn't started
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	checksum, err := sc.checksum()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := s.scriptContext(ctx)
+	defer cancel()
+
+	var addErr error
+
+	start := time.Now()
+	callback := func(exec sqlx.Execer) error {
+		entry := store.NewSchemaScriptSuccess(sc.name, version)
+		entry.Checksum = checksum
+		entry.DurationMS = time.Since(start).Milliseconds()
+		addErr = s.scripter.AddWithContext(ctx, exec, entry)
+
+		return addErr
+	}
+
+	if sc.migration != nil {
+		err = s.applier.ApplyFuncContext(ctx, initdb.Func(sc.migration.up), callback)
+	} else {
+		err = s.applier.ApplyScriptFSContext(ctx, sc.fsys, sc.file, callback)
+	}
+
+	if addErr != nil {
+		return addErr
+	}
+
+	if err != nil {
+		entry := store.NewSchemaScriptError(sc.name, version, err.Error())
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			entry = store.NewSchemaScriptTimeout(sc.name, version, err.Error())
+		}
+
+		entry.DurationMS = time.Since(start).Milliseconds()
+
+		// the failure is logged even if the context was cancelled or timed out
+		msg := fmt.Errorf("failed to execute script %s: %w", sc.name, err)
+		if err := s.scripter.AddContext(context.WithoutCancel(ctx), entry); err != nil {
+			msg = fmt.Errorf("original error: %v, following error: %w", msg, err)
+		}
+
+		return msg
+	}
 
 	return nil
 }
 
+// revertScript reverts a script and removes its log entries.
+func (s *Schema) revertScript(ctx context.Context, sc *script) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, cancel := s.scriptContext(ctx)
+	defer cancel()
+
+	callback := func(exec sqlx.Execer) error {
+		return s.scripter.RemoveWithContext(ctx, exec, sc.name)
+	}
+
+	if sc.migration != nil {
+		return s.applier.ApplyFuncContext(ctx, initdb.Func(sc.migration.down), callback)
+	}
+
+	return s.applier.RevertScriptFSContext(ctx, sc.fsys, sc.file, callback)
+}
+
+// scriptContext returns the context for a single script limited by the script timeout.
+func (s *Schema) scriptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.scriptTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, s.scriptTimeout)
+}
+
 // RevertLast reverts the last applied script. If it is repeatedly called, it reverts every time one script: means if
 // you run it twice it reverts the last two scripts and so on.
-// A path to the sql scripts needs to be provided. It reverts only files with ending ".sql", sub folders are ignored.
+// A path to the sql scripts needs to be provided. It reverts only files with ending ".sql", sub folders are ignored
+// unless WithRecursiveScan() is used. Several paths can be separated by os.PathListSeparator, see Upgrade().
 func (s *Schema) RevertLast(path string) error {
 	return s.RevertN(path, 1)
 }
 
+// RevertLastFS does the same as RevertLast but takes the scripts from the given file system, see UpgradeFS().
+func (s *Schema) RevertLastFS(fsys fs.FS) error {
+	return s.RevertNFS(fsys, 1)
+}
+
 // RevertAll reverts the all applied scripts.
-// A path to the sql scripts needs to be provided. It reverts only files with ending ".sql", sub folders are ignored.
+// A path to the sql scripts needs to be provided. It reverts only files with ending ".sql", sub folders are ignored
+// unless WithRecursiveScan() is used. Several paths can be separated by os.PathListSeparator, see Upgrade().
 func (s *Schema) RevertAll(path string) error {
 	return s.RevertN(path, -1)
 }
 
+// RevertAllFS does the same as RevertAll but takes the scripts from the given file system, see UpgradeFS().
+func (s *Schema) RevertAllFS(fsys fs.FS) error {
+	return s.RevertNFS(fsys, -1)
+}
+
 // RevertN reverts the number of n applied scripts. RevertLast() and RevertAll() are just shortcuts to this method.
-// A path to the sql scripts needs to be provided. It reverts only files with ending ".sql", sub folders are ignored.
+// A path to the sql scripts needs to be provided. It reverts only files with ending ".sql", sub folders are ignored
+// unless WithRecursiveScan() is used. Several paths can be separated by os.PathListSeparator, see Upgrade().
 // Also the numOfScripts (number of scripts) to reverts needs to be provided. If the number is -1 or greater than
 // the number of files in path it reverts all.
+// Repeatable scripts are only reverted if all scripts are reverted, they are reverted first.
 func (s *Schema) RevertN(path string, numOfScripts int) error {
-	executedScripts, err := s.Scripter.GetAll()
+	return s.RevertNContext(context.Background(), path, numOfScripts)
+}
+
+// RevertNContext does the same as RevertN but stops if the context is done, see UpgradeContext().
+func (s *Schema) RevertNContext(ctx context.Context, path string, numOfScripts int) error {
+	return s.withLock(ctx, func(ctx context.Context) error {
+		return s.revertN(ctx, dirSource(path), numOfScripts)
+	})
+}
+
+// RevertNFS does the same as RevertN but takes the scripts from the given file system, see UpgradeFS().
+func (s *Schema) RevertNFS(fsys fs.FS, numOfScripts int) error {
+	return s.RevertNFSContext(context.Background(), fsys, numOfScripts)
+}
+
+// RevertNFSContext does the same as RevertNFS but stops if the context is done, see UpgradeContext().
+func (s *Schema) RevertNFSContext(ctx context.Context, fsys fs.FS, numOfScripts int) error {
+	return s.withLock(ctx, func(ctx context.Context) error {
+		return s.revertN(ctx, fsSource(fsys), numOfScripts)
+	})
+}
+
+func (s *Schema) revertN(ctx context.Context, src source, numOfScripts int) error {
+	executedScripts, err := s.scripter.GetAllContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -138,78 +508,121 @@ func (s *Schema) RevertN(path string, numOfScripts int) error {
 	/**
 	1. scan files reverse
 	2. iterate over files in directory
-	2a. check if file is applied
+	2a. check if file is applied, repeatable scripts are only reverted if all scripts are reverted
 	2b. if 2a) is true load each file revert from database
-	2c. remove executed script from 2b) from store as success or error
+	2c. remove executed script from 2b) from store within the transaction of 2b)
 	3. return after numOfScripts was reverted, -1 means all
+	4. emit the events of the revert and each script to the subscribers
 	*/
-	files, err := sqlfile.ScanReverse(path)
+	scripts, err := src.scanReverse(s.migrations, s.scanOptions)
 	if err != nil {
 		return err
 	}
 
-	counter := 0
-	progressBar := s.startProgressBar(numOfScripts)
-
-	if numOfScripts < 1 {
-		progressBar = s.startProgressBar(len(files))
+	if err = s.migrateScriptNames(ctx, scripts, executedScripts); err != nil {
+		return err
 	}
 
-	for _, f := range files {
-		progressBar.Increment()
+	reverts := make([]*script, 0, len(scripts))
 
-		if !executedScripts.ScriptExecuted(f) {
+	for _, sc := range scripts {
+		if !executedScripts.ScriptExecuted(sc.name) || sc.repeatable && numOfScripts > 0 {
 			continue
 		}
 
-		if err = s.Applier.RevertScript(f); err != nil {
-			return err
-		}
-
-		if err = s.Scripter.Remove(f); err != nil {
-			return err
-		}
-
-		counter++
-		if numOfScripts > 0 && counter >= numOfScripts {
+		reverts = append(reverts, sc)
+		if numOfScripts > 0 && len(reverts) >= numOfScripts {
 			break
 		}
 	}
 
-	progressBar.Finish()
-
-	return nil
+	return s.run(DirectionDown, "", reverts, func(sc *script) error {
+		return s.revertScript(ctx, sc)
+	})
 }
 
 // Recreate reverts all applied scripts and apply them again. Internally it usues RevertAll() and Upgrade().
 func (s *Schema) Recreate(path string, version string) error {
-	var err error
-	if err = s.RevertAll(path); err != nil {
+	return s.RecreateContext(context.Background(), path, version)
+}
+
+// RecreateContext does the same as Recreate but stops if the context is done, see UpgradeContext().
+func (s *Schema) RecreateContext(ctx context.Context, path string, version string) error {
+	return s.withLock(ctx, func(ctx context.Context) error {
+		return s.recreate(ctx, dirSource(path), version)
+	})
+}
+
+// RecreateFS does the same as Recreate but takes the scripts from the given file system, see UpgradeFS().
+func (s *Schema) RecreateFS(fsys fs.FS, version string) error {
+	return s.RecreateFSContext(context.Background(), fsys, version)
+}
+
+// RecreateFSContext does the same as RecreateFS but stops if the context is done, see UpgradeContext().
+func (s *Schema) RecreateFSContext(ctx context.Context, fsys fs.FS, version string) error {
+	return s.withLock(ctx, func(ctx context.Context) error {
+		return s.recreate(ctx, fsSource(fsys), version)
+	})
+}
+
+func (s *Schema) recreate(ctx context.Context, src source, version string) error {
+	if err := s.revertN(ctx, src, -1); err != nil {
 		return err
 	}
 
-	if err = s.Applier.ReInit(); err != nil {
+	if err := s.applier.ReInit(); err != nil {
 		return err
 	}
 
-	return s.Upgrade(path, version)
+	return s.upgrade(ctx, src, version)
 }
 
-func checkDatabaseExists(db store.DatabaseConnector) bool {
-	var counter []uint32
+// init creates the tables needed by this package if they don't exist or migrates them if they were created by a
+// former version of this package, see initdb.MetaVersion.
+func (s *Schema) init() error {
+	var versions []int
 
-	q := "SELECT count(id) FROM schema_script;"
-	if err := db.Select(&counter, q); err != nil {
-		return false
+	q := fmt.Sprintf("SELECT version FROM %s WHERE id = 1;", s.tables.Qualified(s.dialect, s.tables.Meta))
+
+	err := s.db.Select(&versions, q)
+	if err == nil && (len(versions) == 0 || versions[0] >= initdb.MetaVersion) {
+		return nil
+	}
+
+	return s.applier.Init()
+}
+
+// migrateScriptNames stores the relative names of the entries recorded with the full path of a script by former
+// versions, see relativeNames(). It is done once, afterwards the entries match the names of the scripts.
+func (s *Schema) migrateScriptNames(
+	ctx context.Context,
+	scripts []*script,
+	executedScripts store.SchemaScriptCollection,
+) error {
+	for oldName, newName := range relativeNames(scripts, executedScripts) {
+		if err := s.scripter.RenameContext(ctx, oldName, newName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// executedScripts returns the logged script executions or an empty collection if the database wasn't initialised.
+func (s *Schema) executedScripts(ctx context.Context) (store.SchemaScriptCollection, error) {
+	if !checkDatabaseExists(s.db, s.dialect, s.tables) {
+		return store.SchemaScriptCollection{}, nil
 	}
 
-	return true
+	return s.scripter.GetAllContext(ctx)
 }
 
-func (s *Schema) startProgressBar(count int) Progressor {
-	if s.progressBar {
-		return pb.StartNew(count)
+func checkDatabaseExists(db store.DatabaseConnector, d dialect.Dialect, tables store.Tables) bool {
+	var counter []uint32
+
+	if err := db.Select(&counter, d.TableExists(tables.Namespace, tables.Script)); err != nil {
+		return false
 	}
 
-	return &bar.BlackHole{}
+	return len(counter) == 0 || counter[0] > 0
 }
@@ -1,20 +1,27 @@
 package schema_test
 
 import (
+	"context"
+	"embed"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/rebel-l/go-utils/osutils"
 
 	"github.com/rebel-l/schema"
+	"github.com/rebel-l/schema/dialect"
+	"github.com/rebel-l/schema/initdb"
 	"github.com/rebel-l/schema/mocks/schema_mock"
 	"github.com/rebel-l/schema/mocks/store_mock"
 	"github.com/rebel-l/schema/store"
 	"github.com/rebel-l/schema/utils/testdb"
 
 	"github.com/golang/mock/gomock"
+	"github.com/jmoiron/sqlx"
 )
 
 func TestSchema_Upgrade_Happy(t *testing.T) {
@@ -44,19 +51,24 @@ func TestSchema_Upgrade_Happy(t *testing.T) {
 
 			mockApplier := schema_mock.NewMockApplier(ctrl)
 			mockApplier.EXPECT().Init().Times(1).Return(nil)
-			mockApplier.EXPECT().ApplyScript(gomock.Eq("./testdata/unit/001.sql")).Times(1).Return(nil)
-			mockApplier.EXPECT().ApplyScript(gomock.Eq("./testdata/unit/002.sql")).Times(1).Return(nil)
+			mockApplier.EXPECT().
+				ApplyScriptFSContext(gomock.Any(), gomock.Any(), "001.sql", gomock.Any()).Times(1).
+				DoAndReturn(runCallbacks(mockDB))
+			mockApplier.EXPECT().
+				ApplyScriptFSContext(gomock.Any(), gomock.Any(), "002.sql", gomock.Any()).Times(1).
+				DoAndReturn(runCallbacks(mockDB))
 
 			mockScripter := schema_mock.NewMockScripter(ctrl)
-			mockScripter.EXPECT().GetAll().Times(1).Return(store.SchemaScriptCollection{}, nil)
-			mockScripter.EXPECT().Add(gomock.Any()).Times(2).Return(nil)
+			mockScripter.EXPECT().GetAllContext(gomock.Any()).Times(1).Return(store.SchemaScriptCollection{}, nil)
+			mockScripter.EXPECT().AddWithContext(gomock.Any(), mockDB, gomock.Any()).Times(2).Return(nil)
+			mockScripter.EXPECT().AddContext(gomock.Any(), gomock.Any()).Times(0)
 
-			s := schema.New(mockDB)
+			opts := []schema.Option{schema.WithApplier(mockApplier), schema.WithScripter(mockScripter)}
 			if withProgressBar {
-				s.WithProgressBar()
+				opts = append(opts, schema.WithProgressBar())
 			}
-			s.Applier = mockApplier
-			s.Scripter = mockScripter
+
+			s := newSchema(t, mockDB, opts...)
 
 			if err := s.Upgrade("./testdata/unit", ""); err != nil {
 				t.Errorf("Expected no errors but got %s", err)
@@ -65,6 +77,59 @@ func TestSchema_Upgrade_Happy(t *testing.T) {
 	}
 }
 
+func TestSchema_WithDialect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().
+		Select(gomock.Any(), dialect.Postgres{}.TableExists("", "schema_script")).
+		Return(errors.New("failed")) // nolint: goerr113
+
+	s := newSchema(t, mockDB, schema.WithDialect(dialect.Postgres{}))
+
+	plan, err := s.PlanUpgrade("./testdata/unit")
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if plan.Len() != 2 {
+		t.Errorf("Expected 2 steps but got %d", plan.Len())
+	}
+}
+
+func TestNew_Unhappy_InvalidOption(t *testing.T) {
+	testCases := []struct {
+		name   string
+		option schema.Option
+	}{
+		{name: "dialect", option: schema.WithDialect(nil)},
+		{name: "table name", option: schema.WithTableName("")},
+		{name: "namespace", option: schema.WithNamespace("")},
+		{name: "scripter", option: schema.WithScripter(nil)},
+		{name: "applier", option: schema.WithApplier(nil)},
+		{name: "locker", option: schema.WithLocker(nil)},
+		{name: "subscriber", option: schema.WithSubscriber(nil)},
+		{name: "timeout", option: schema.WithTimeout(-time.Second)},
+		{name: "script timeout", option: schema.WithScriptTimeout(-time.Second)},
+		{name: "lock timeout", option: schema.WithLock(-time.Second)},
+		{name: "lock ttl", option: schema.WithLockTTL(0)},
+		{name: "lock ttl too short", option: schema.WithLockTTL(time.Nanosecond)},
+		{name: "out of order policy", option: schema.WithOutOfOrder(schema.OutOfOrderPolicy(42))},
+		{name: "include pattern", option: schema.WithInclude("[")},
+		{name: "exclude pattern", option: schema.WithExclude("[")},
+	}
+
+	for _, testCase := range testCases {
+		option := testCase.option
+		t.Run(testCase.name, func(t *testing.T) {
+			if _, err := schema.New(nil, schema.WithoutValidation(), option); !errors.Is(err, schema.ErrInvalidOption) {
+				t.Errorf("Expected error %s but got %v", schema.ErrInvalidOption, err)
+			}
+		})
+	}
+}
+
 func TestSchema_Upgrade_Unhappy_GetAllError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -77,13 +142,11 @@ func TestSchema_Upgrade_Unhappy_GetAllError(t *testing.T) {
 
 	mockScripter := schema_mock.NewMockScripter(ctrl)
 	mockScripter.EXPECT().
-		GetAll().
+		GetAllContext(gomock.Any()).
 		Times(1).
 		Return(store.SchemaScriptCollection{}, errors.New("failed")) // nolint: goerr113
 
-	s := schema.New(mockDB)
-	s.Applier = mockApplier
-	s.Scripter = mockScripter
+	s := newSchema(t, mockDB, schema.WithApplier(mockApplier), schema.WithScripter(mockScripter))
 
 	if err := s.Upgrade("./testdata/unit", ""); err == nil {
 		t.Error("Expected error is returned on failed database operation")
@@ -101,11 +164,9 @@ func TestSchema_Upgrade_Unhappy_InitError(t *testing.T) {
 	mockApplier.EXPECT().Init().Return(errors.New("failed init")) // nolint: goerr113
 
 	mockScripter := schema_mock.NewMockScripter(ctrl)
-	mockScripter.EXPECT().GetAll().Times(0)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Times(0)
 
-	s := schema.New(mockDB)
-	s.Applier = mockApplier
-	s.Scripter = mockScripter
+	s := newSchema(t, mockDB, schema.WithApplier(mockApplier), schema.WithScripter(mockScripter))
 
 	if err := s.Upgrade("./testdata/unit", ""); err == nil {
 		t.Error("Expected error is returned on failed database initialisation")
@@ -116,25 +177,23 @@ func TestSchema_Upgrade_Unhappy_ApplyError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	expected := "failed to execute script ./testdata/unit/001.sql: failed apply"
+	expected := "failed to execute script 001.sql: failed apply"
 
 	mockDB := getMockDB(ctrl, false)
 	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Times(1).Return(nil)
 
 	mockScripter := schema_mock.NewMockScripter(ctrl)
 	res := store.SchemaScriptCollection{}
-	mockScripter.EXPECT().GetAll().Times(1).Return(res, nil)
-	mockScripter.EXPECT().Add(gomock.Any()).Return(nil)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Times(1).Return(res, nil)
+	mockScripter.EXPECT().AddContext(gomock.Any(), gomock.Any()).Return(nil)
 
 	mockApplier := schema_mock.NewMockApplier(ctrl)
 
 	mockApplier.EXPECT().
-		ApplyScript("./testdata/unit/001.sql").
+		ApplyScriptFSContext(gomock.Any(), gomock.Any(), "001.sql", gomock.Any()).
 		Return(errors.New("failed apply")) // nolint: goerr113
 
-	s := schema.New(mockDB)
-	s.Applier = mockApplier
-	s.Scripter = mockScripter
+	s := newSchema(t, mockDB, schema.WithApplier(mockApplier), schema.WithScripter(mockScripter))
 
 	err := s.Upgrade("./testdata/unit", "")
 	if err == nil {
@@ -157,15 +216,16 @@ func TestSchema_Upgrade_Unhappy_AddError(t *testing.T) {
 
 	mockScripter := schema_mock.NewMockScripter(ctrl)
 	res := store.SchemaScriptCollection{}
-	mockScripter.EXPECT().GetAll().Times(1).Return(res, nil)
-	mockScripter.EXPECT().Add(gomock.Any()).Return(errors.New(expected)) // nolint: goerr113
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Times(1).Return(res, nil)
+	mockScripter.EXPECT().
+		AddWithContext(gomock.Any(), mockDB, gomock.Any()).
+		Return(errors.New(expected)) // nolint: goerr113
+	mockScripter.EXPECT().AddContext(gomock.Any(), gomock.Any()).Times(0)
 
 	mockApplier := schema_mock.NewMockApplier(ctrl)
-	mockApplier.EXPECT().ApplyScript("./testdata/unit/001.sql").Return(nil)
+	mockApplier.EXPECT().ApplyScriptFSContext(gomock.Any(), gomock.Any(), "001.sql", gomock.Any()).DoAndReturn(runCallbacks(mockDB))
 
-	s := schema.New(mockDB)
-	s.Applier = mockApplier
-	s.Scripter = mockScripter
+	s := newSchema(t, mockDB, schema.WithApplier(mockApplier), schema.WithScripter(mockScripter))
 
 	err := s.Upgrade("./testdata/unit", "")
 	if err == nil {
@@ -183,22 +243,22 @@ func TestSchema_Upgrade_Unhappy_ApplyErrorAddError(t *testing.T) {
 
 	errMsg1 := "failed apply"
 	errMsg2 := "failed add"
-	expected := "original error: failed to execute script ./testdata/unit/001.sql: failed apply, following error: failed add" // nolint: lll
+	expected := "original error: failed to execute script 001.sql: failed apply, following error: failed add"
 
 	mockDB := getMockDB(ctrl, false)
 	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Times(1).Return(nil)
 
 	mockScripter := schema_mock.NewMockScripter(ctrl)
 	res := store.SchemaScriptCollection{}
-	mockScripter.EXPECT().GetAll().Times(1).Return(res, nil)
-	mockScripter.EXPECT().Add(gomock.Any()).Return(errors.New(errMsg2)) // nolint: goerr113
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Times(1).Return(res, nil)
+	mockScripter.EXPECT().AddContext(gomock.Any(), gomock.Any()).Return(errors.New(errMsg2)) // nolint: goerr113
 
 	mockApplier := schema_mock.NewMockApplier(ctrl)
-	mockApplier.EXPECT().ApplyScript("./testdata/unit/001.sql").Return(errors.New(errMsg1)) // nolint: goerr113
+	mockApplier.EXPECT().
+		ApplyScriptFSContext(gomock.Any(), gomock.Any(), "001.sql", gomock.Any()).
+		Return(errors.New(errMsg1)) // nolint: goerr113
 
-	s := schema.New(mockDB)
-	s.Applier = mockApplier
-	s.Scripter = mockScripter
+	s := newSchema(t, mockDB, schema.WithApplier(mockApplier), schema.WithScripter(mockScripter))
 
 	err := s.Upgrade("./testdata/unit", "")
 	if err == nil {
@@ -216,19 +276,17 @@ func TestSchema_RevertLast_Unhappy_RevertError(t *testing.T) {
 
 	mockApplier := schema_mock.NewMockApplier(ctrl)
 	mockApplier.EXPECT().
-		RevertScript("./testdata/unit/002.sql").
+		RevertScriptFSContext(gomock.Any(), gomock.Any(), "002.sql", gomock.Any()).
 		Return(errors.New("failed")) // nolint: goerr113
 
 	mockScripter := schema_mock.NewMockScripter(ctrl)
 	res := store.SchemaScriptCollection{&store.SchemaScript{
-		ScriptName: "./testdata/unit/002.sql",
+		ScriptName: "002.sql",
 		Status:     store.StatusSuccess,
 	}}
-	mockScripter.EXPECT().GetAll().Times(1).Return(res, nil)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Times(1).Return(res, nil)
 
-	s := schema.New(getMockDB(ctrl, true))
-	s.Applier = mockApplier
-	s.Scripter = mockScripter
+	s := newSchema(t, getMockDB(ctrl, true), schema.WithApplier(mockApplier), schema.WithScripter(mockScripter))
 
 	if err := s.RevertLast("./testdata/unit"); err == nil {
 		t.Error("Expected error is returned on failed revert")
@@ -239,22 +297,22 @@ func TestSchema_RevertLast_Unhappy_RemoveError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockDB := getMockDB(ctrl, true)
+
 	mockApplier := schema_mock.NewMockApplier(ctrl)
-	mockApplier.EXPECT().RevertScript("./testdata/unit/002.sql").Return(nil)
+	mockApplier.EXPECT().RevertScriptFSContext(gomock.Any(), gomock.Any(), "002.sql", gomock.Any()).DoAndReturn(runCallbacks(mockDB))
 
 	mockScripter := schema_mock.NewMockScripter(ctrl)
 	res := store.SchemaScriptCollection{&store.SchemaScript{
-		ScriptName: "./testdata/unit/002.sql",
+		ScriptName: "002.sql",
 		Status:     store.StatusSuccess,
 	}}
-	mockScripter.EXPECT().GetAll().Times(1).Return(res, nil)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Times(1).Return(res, nil)
 	mockScripter.EXPECT().
-		Remove("./testdata/unit/002.sql").
+		RemoveWithContext(gomock.Any(), mockDB, "002.sql").
 		Return(errors.New("failed")) // nolint: goerr113
 
-	s := schema.New(getMockDB(ctrl, true))
-	s.Applier = mockApplier
-	s.Scripter = mockScripter
+	s := newSchema(t, mockDB, schema.WithApplier(mockApplier), schema.WithScripter(mockScripter))
 
 	if err := s.RevertLast("./testdata/unit"); err == nil {
 		t.Error("Expected error is returned on failed remove")
@@ -266,16 +324,14 @@ func TestSchema_RevertLast_Unhappy_GetAllError(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockApplier := schema_mock.NewMockApplier(ctrl)
-	mockApplier.EXPECT().RevertScript("./testdata/unit/002.sql").Times(0)
+	mockApplier.EXPECT().RevertScriptFSContext(gomock.Any(), gomock.Any(), "002.sql").Times(0)
 
 	mockScripter := schema_mock.NewMockScripter(ctrl)
 	mockScripter.EXPECT().
-		GetAll().
+		GetAllContext(gomock.Any()).
 		Return(nil, errors.New("failed getting data")) // nolint: goerr113
 
-	s := schema.New(getMockDB(ctrl, true))
-	s.Applier = mockApplier
-	s.Scripter = mockScripter
+	s := newSchema(t, getMockDB(ctrl, true), schema.WithApplier(mockApplier), schema.WithScripter(mockScripter))
 
 	if err := s.RevertLast("./testdata/unit"); err == nil {
 		t.Error("Expected error is returned on failed operation to load data")
@@ -286,21 +342,21 @@ func TestSchema_Recreate_Unhappy_ReInitError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockDB := getMockDB(ctrl, true)
+
 	mockApplier := schema_mock.NewMockApplier(ctrl)
-	mockApplier.EXPECT().RevertScript("./testdata/unit/002.sql").Return(nil)
+	mockApplier.EXPECT().RevertScriptFSContext(gomock.Any(), gomock.Any(), "002.sql", gomock.Any()).DoAndReturn(runCallbacks(mockDB))
 	mockApplier.EXPECT().ReInit().Return(errors.New("failed to reinit db")) // nolint: goerr113
 
 	mockScripter := schema_mock.NewMockScripter(ctrl)
 	res := store.SchemaScriptCollection{&store.SchemaScript{
-		ScriptName: "./testdata/unit/002.sql",
+		ScriptName: "002.sql",
 		Status:     store.StatusSuccess,
 	}}
-	mockScripter.EXPECT().GetAll().Times(1).Return(res, nil)
-	mockScripter.EXPECT().Remove(gomock.Any()).Return(nil)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Times(1).Return(res, nil)
+	mockScripter.EXPECT().RemoveWithContext(gomock.Any(), mockDB, gomock.Any()).Return(nil)
 
-	s := schema.New(getMockDB(ctrl, true))
-	s.Applier = mockApplier
-	s.Scripter = mockScripter
+	s := newSchema(t, mockDB, schema.WithApplier(mockApplier), schema.WithScripter(mockScripter))
 
 	if err := s.Recreate("./testdata/unit", ""); err == nil {
 		t.Error("Expected error is returned on failed recreate")
@@ -328,16 +384,14 @@ func TestSchema_Upgrade_Unhappy_NotExistingPath(t *testing.T) {
 			defer ctrl.Finish()
 
 			mockScripter := schema_mock.NewMockScripter(ctrl)
-			mockScripter.EXPECT().GetAll().Times(1).Return(store.SchemaScriptCollection{}, nil)
+			mockScripter.EXPECT().GetAllContext(gomock.Any()).Times(1).Return(store.SchemaScriptCollection{}, nil)
 
-			s := schema.New(getMockDB(ctrl, true))
-			s.Scripter = mockScripter
+			s := newSchema(t, getMockDB(ctrl, true), schema.WithScripter(mockScripter))
 
 			mockDB := getMockDB(ctrl, false)
 			mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Times(1).Return(nil)
 
-			s = schema.New(mockDB)
-			s.Scripter = mockScripter
+			s = newSchema(t, mockDB, schema.WithScripter(mockScripter))
 
 			if err := s.Upgrade(path, ""); err == nil {
 				t.Errorf("Expected an error on call with not existing path")
@@ -367,10 +421,9 @@ func TestSchema_RevertLast_Unhappy_NotExistingPath(t *testing.T) {
 			defer ctrl.Finish()
 
 			mockScripter := schema_mock.NewMockScripter(ctrl)
-			mockScripter.EXPECT().GetAll().Times(1).Return(store.SchemaScriptCollection{}, nil)
+			mockScripter.EXPECT().GetAllContext(gomock.Any()).Times(1).Return(store.SchemaScriptCollection{}, nil)
 
-			s := schema.New(getMockDB(ctrl, true))
-			s.Scripter = mockScripter
+			s := newSchema(t, getMockDB(ctrl, true), schema.WithScripter(mockScripter))
 
 			if err := s.RevertLast(path); err == nil {
 				t.Errorf("Expected an error on call with not existing path")
@@ -400,10 +453,9 @@ func TestSchema_Recreate_Unhappy_NotExistingPath(t *testing.T) {
 			defer ctrl.Finish()
 
 			mockScripter := schema_mock.NewMockScripter(ctrl)
-			mockScripter.EXPECT().GetAll().Times(1).Return(store.SchemaScriptCollection{}, nil)
+			mockScripter.EXPECT().GetAllContext(gomock.Any()).Times(1).Return(store.SchemaScriptCollection{}, nil)
 
-			s := schema.New(getMockDB(ctrl, true))
-			s.Scripter = mockScripter
+			s := newSchema(t, getMockDB(ctrl, true), schema.WithScripter(mockScripter))
 
 			if err := s.Recreate(path, ""); err == nil {
 				t.Errorf("Expected an error on call with not existing path")
@@ -412,6 +464,18 @@ func TestSchema_Recreate_Unhappy_NotExistingPath(t *testing.T) {
 	}
 }
 
+// newSchema returns a Schema configured by the options and fails the test if an option is invalid.
+func newSchema(t *testing.T, db store.DatabaseConnector, opts ...schema.Option) schema.Schema {
+	t.Helper()
+
+	s, err := schema.New(db, opts...)
+	if err != nil {
+		t.Fatalf("failed to create schema: %s", err)
+	}
+
+	return s
+}
+
 func getMockDB(ctrl *gomock.Controller, dummy bool) *store_mock.MockDatabaseConnector {
 	db := store_mock.NewMockDatabaseConnector(ctrl)
 
@@ -422,6 +486,123 @@ func getMockDB(ctrl *gomock.Controller, dummy bool) *store_mock.MockDatabaseConn
 	return db
 }
 
+func runCallbacks(exec sqlx.Execer) func(context.Context, fs.FS, string, ...initdb.Callback) error {
+	return func(_ context.Context, _ fs.FS, _ string, callbacks ...initdb.Callback) error {
+		for _, c := range callbacks {
+			if err := c(exec); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+func TestSchema_Upgrade_Integration_MetaMigration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_meta_migration.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	// table created by the release before the meta-version was introduced
+	q := `CREATE TABLE schema_script (
+  id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  script_name TEXT NOT NULL,
+  executed_at DATETIME NOT NULL,
+  execution_status VARCHAR(100) NOT NULL,
+  app_version CHAR(30) NULL,
+  error_msg TEXT NULL,
+  checksum CHAR(64) NOT NULL DEFAULT ''
+);`
+	if _, err = db.Exec(q); err != nil {
+		t.Fatalf("failed to create table: %s", err)
+	}
+
+	if _, err = db.Exec(`INSERT INTO schema_script (script_name, executed_at, execution_status, app_version, error_msg)
+VALUES ('001.sql', '2020-01-01T00:00:00Z', 'success', '', '');`); err != nil {
+		t.Fatalf("failed to add entry: %s", err)
+	}
+
+	// the tables are migrated while holding the lock
+	s := newSchema(t, db, schema.WithLock(time.Second))
+	if err = s.Upgrade("./testdata/upgrade/happy", ""); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	data, err := store.NewSchemaScriptMapper(db).GetAll()
+	if err != nil {
+		t.Fatalf("not able get rows from table: %s", err)
+	}
+
+	if data.Len() != 2 || data[1].ScriptName != "002.sql" || data[1].ExecutedBy == "" {
+		t.Errorf("Expected only 002.sql to be applied and logged with the new columns but got %d entries", data.Len())
+	}
+
+	var versions []int
+	if err = db.Select(&versions, "SELECT version FROM schema_meta;"); err != nil {
+		t.Fatalf("not able to read meta-version: %s", err)
+	}
+
+	if len(versions) != 1 || versions[0] != initdb.MetaVersion {
+		t.Errorf("Expected meta-version %d but got %v", initdb.MetaVersion, versions)
+	}
+}
+
+func TestSchema_Upgrade_Integration_Tables(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_tables.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	// an attached database is bound to its connection
+	db.(*sqlx.DB).SetMaxOpenConns(1)
+
+	billing := "./testdata/tmp/schema_tables_billing.db"
+	if err = os.RemoveAll(billing); err != nil {
+		t.Fatalf("failed to remove database: %s", err)
+	}
+
+	if _, err = db.Exec(fmt.Sprintf("ATTACH DATABASE '%s' AS billing;", billing)); err != nil {
+		t.Fatalf("failed to attach database: %s", err)
+	}
+
+	s := newSchema(t, db)
+	if err = s.Upgrade("./testdata/migrate", ""); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	other := newSchema(t, db,
+		schema.WithNamespace("billing"),
+		schema.WithTableName("billing_script"),
+		schema.WithLock(time.Minute),
+	)
+
+	if err = other.Upgrade("./testdata/migrate", ""); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if err = other.RevertLast("./testdata/migrate"); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	checkTable("schema_script", db, t, 3)
+	checkTable(`"billing"."billing_script"`, db, t, 2)
+	checkTable(`"billing"."billing_script_meta"`, db, t, 1)
+	checkTable(`"billing"."billing_script_lock"`, db, t, 0)
+}
+
 func TestSchema_Upgrage_Integration_Happy(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipped because of long running")
@@ -434,25 +615,25 @@ func TestSchema_Upgrage_Integration_Happy(t *testing.T) {
 
 	defer testdb.ShutdownDB(db, t)
 
-	s := schema.New(db)
+	s := newSchema(t, db)
 
 	err = s.Upgrade("./testdata/upgrade/happy", "")
 	if err != nil {
 		t.Errorf("Expected no error but got %s", err)
 	}
 
-	data, err := s.Scripter.GetAll()
+	data, err := store.NewSchemaScriptMapper(db).GetAll()
 	if err != nil {
 		t.Fatalf("not able get rows from table: %s", err)
 	}
 
 	expected := store.SchemaScriptCollection{
 		&store.SchemaScript{
-			ScriptName: "./testdata/upgrade/happy/001.sql",
+			ScriptName: "001.sql",
 			Status:     store.StatusSuccess,
 		},
 		&store.SchemaScript{
-			ScriptName: "./testdata/upgrade/happy/002.sql",
+			ScriptName: "002.sql",
 			Status:     store.StatusSuccess,
 		},
 	}
@@ -462,6 +643,64 @@ func TestSchema_Upgrage_Integration_Happy(t *testing.T) {
 	checkTable("something_new", db, t, 0)
 }
 
+//go:embed testdata/upgrade/happy/*.sql
+var happyScripts embed.FS
+
+func TestSchema_UpgradeFS_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_execute_upgrade_fs.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	fsys, err := fs.Sub(happyScripts, "testdata/upgrade/happy")
+	if err != nil {
+		t.Fatalf("failed to get sub file system: %s", err)
+	}
+
+	s := newSchema(t, db)
+
+	if err = s.UpgradeFS(fsys, ""); err != nil {
+		t.Fatalf("Expected no error on upgrade but got %s", err)
+	}
+
+	data, err := store.NewSchemaScriptMapper(db).GetAll()
+	if err != nil {
+		t.Fatalf("not able get rows from table: %s", err)
+	}
+
+	expected := store.SchemaScriptCollection{
+		&store.SchemaScript{
+			ScriptName: "001.sql",
+			Status:     store.StatusSuccess,
+		},
+		&store.SchemaScript{
+			ScriptName: "002.sql",
+			Status:     store.StatusSuccess,
+		},
+	}
+
+	checkScriptTable("TestSchema_UpgradeFS_Integration", expected, data, t)
+	checkTable("something", db, t, 0)
+	checkTable("something_new", db, t, 0)
+
+	if err = s.RevertLastFS(fsys); err != nil {
+		t.Fatalf("Expected no error on revert but got %s", err)
+	}
+
+	data, err = store.NewSchemaScriptMapper(db).GetAll()
+	if err != nil {
+		t.Fatalf("not able get rows from table: %s", err)
+	}
+
+	checkScriptTable("TestSchema_UpgradeFS_Integration", expected[:1], data, t)
+}
+
 func TestSchema_Upgrade_Integration_Happy_TwoSteps(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipped because of long running")
@@ -485,7 +724,7 @@ func TestSchema_Upgrade_Integration_Happy_TwoSteps(t *testing.T) {
 		}
 	}()
 
-	s := schema.New(db)
+	s := newSchema(t, db)
 
 	expected := step1(t, db, s)
 	step2(t, db, s, expected)
@@ -505,14 +744,14 @@ func step1(t *testing.T, db store.DatabaseConnector, s schema.Schema) store.Sche
 		t.Errorf("Expected no error but got %s", err)
 	}
 
-	data, err := s.Scripter.GetAll()
+	data, err := store.NewSchemaScriptMapper(db).GetAll()
 	if err != nil {
 		t.Fatalf("not able get rows from table: %s", err)
 	}
 
 	expected := store.SchemaScriptCollection{
 		&store.SchemaScript{
-			ScriptName: "./testdata/upgrade/two_steps/001.sql",
+			ScriptName: "001.sql",
 			Status:     store.StatusSuccess,
 		},
 	}
@@ -537,13 +776,13 @@ func step2(t *testing.T, db store.DatabaseConnector, s schema.Schema, expected s
 		t.Errorf("Expected no error but got %s", err)
 	}
 
-	data, err := s.Scripter.GetAll()
+	data, err := store.NewSchemaScriptMapper(db).GetAll()
 	if err != nil {
 		t.Fatalf("not able get rows from table: %s", err)
 	}
 
 	expected = append(expected, &store.SchemaScript{
-		ScriptName: "./testdata/upgrade/two_steps/002.sql",
+		ScriptName: "002.sql",
 		Status:     store.StatusSuccess,
 	})
 	checkScriptTable("TestSchema_Upgrade_Integration_Happy_TwoSteps - Step 2", expected, data, t)
@@ -551,6 +790,49 @@ func step2(t *testing.T, db store.DatabaseConnector, s schema.Schema, expected s
 	checkTable("something_new", db, t, 0)
 }
 
+func TestSchema_Upgrade_Integration_Rollback(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_execute_upgrade_rollback.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	s := newSchema(t, db)
+	if err = s.Upgrade("./testdata/transaction", ""); err == nil {
+		t.Fatal("Expected error is returned on failing script")
+	}
+
+	data, err := store.NewSchemaScriptMapper(db).GetAll()
+	if err != nil {
+		t.Fatalf("not able get rows from table: %s", err)
+	}
+
+	expected := store.SchemaScriptCollection{
+		&store.SchemaScript{
+			ScriptName: "001.sql",
+			Status:     store.StatusSuccess,
+		},
+		&store.SchemaScript{
+			ScriptName: "002.sql",
+			Status:     store.StatusError,
+			ErrorMsg:   "statement at line 3: no such table: not_existing",
+		},
+	}
+
+	checkScriptTable("TestSchema_Upgrade_Integration_Rollback", expected, data, t)
+	checkTable("something", db, t, 0)
+
+	var counter []uint32
+	if err = db.Select(&counter, "SELECT count(id) FROM something_new;"); err == nil {
+		t.Error("Expected that table of failed script was rolled back")
+	}
+}
+
 func TestSchema_RevertLast_Integration_Happy(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipped because of long running")
@@ -564,23 +846,23 @@ func TestSchema_RevertLast_Integration_Happy(t *testing.T) {
 
 	defer testdb.ShutdownDB(db, t)
 
-	s := schema.New(db)
+	s := newSchema(t, db)
 	if err = s.Upgrade("./testdata/revert", ""); err != nil {
 		t.Fatalf("Expected no error but got %s", err)
 	}
 
-	data, err := s.Scripter.GetAll()
+	data, err := store.NewSchemaScriptMapper(db).GetAll()
 	if err != nil {
 		t.Fatalf("not able get rows from table: %s", err)
 	}
 
 	expected := store.SchemaScriptCollection{
 		&store.SchemaScript{
-			ScriptName: "./testdata/revert/001.sql",
+			ScriptName: "001.sql",
 			Status:     store.StatusSuccess,
 		},
 		&store.SchemaScript{
-			ScriptName: "./testdata/revert/002.sql",
+			ScriptName: "002.sql",
 			Status:     store.StatusSuccess,
 		},
 	}
@@ -595,7 +877,7 @@ func TestSchema_RevertLast_Integration_Happy(t *testing.T) {
 		t.Fatalf("not able to revert: %s", err)
 	}
 
-	data, err = s.Scripter.GetAll()
+	data, err = store.NewSchemaScriptMapper(db).GetAll()
 	if err != nil {
 		t.Fatalf("not able get rows from table: %s", err)
 	}
@@ -618,22 +900,22 @@ func TestSchema_Recreate_Integration_Happy(t *testing.T) {
 	defer testdb.ShutdownDB(db, t)
 
 	// prepare
-	s := schema.New(db)
+	s := newSchema(t, db)
 	if err = s.Upgrade("./testdata/recreate", ""); err != nil {
 		t.Fatalf("Prepare: failed to create data: %s", err)
 	}
 
 	expected := store.SchemaScriptCollection{
 		&store.SchemaScript{
-			ScriptName: "./testdata/recreate/001.sql",
+			ScriptName: "001.sql",
 			Status:     store.StatusSuccess,
 		},
 		&store.SchemaScript{
-			ScriptName: "./testdata/recreate/002.sql",
+			ScriptName: "002.sql",
 			Status:     store.StatusSuccess,
 		},
 		&store.SchemaScript{
-			ScriptName: "./testdata/recreate/003_fake.sql",
+			ScriptName: "003_fake.sql",
 			Status:     store.StatusSuccess,
 		},
 	}
@@ -643,7 +925,7 @@ func TestSchema_Recreate_Integration_Happy(t *testing.T) {
 		t.Fatalf("Prepare: couldn't add fake script: %s", err)
 	}
 
-	data, err := s.Scripter.GetAll()
+	data, err := store.NewSchemaScriptMapper(db).GetAll()
 	if err != nil {
 		t.Fatalf("Prepare: not able get rows from table: %s", err)
 	}
@@ -663,7 +945,7 @@ func TestSchema_Recreate_Integration_Happy(t *testing.T) {
 		t.Fatalf("not able to recreate: %s", err)
 	}
 
-	data, err = s.Scripter.GetAll()
+	data, err = store.NewSchemaScriptMapper(db).GetAll()
 	if err != nil {
 		t.Fatalf("not able get rows from table: %s", err)
 	}
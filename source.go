@@ -0,0 +1,246 @@
+package schema
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rebel-l/schema/sqlfile"
+	"github.com/rebel-l/schema/store"
+)
+
+// source represents the locations of the sql scripts, either directories on disk or a file system like embed.FS.
+type source struct {
+	roots []root
+}
+
+// root represents a single location of sql scripts.
+type root struct {
+	fsys fs.FS
+}
+
+// dirSource returns the source for directories on disk. Several directories are separated by os.PathListSeparator.
+// The scripts are named (and logged) by their path relative to their directory, so the same scripts executed from
+// another path are identified as the same.
+func dirSource(path string) source {
+	src := source{}
+	if path == "" {
+		return src
+	}
+
+	for _, dir := range filepath.SplitList(path) {
+		src.roots = append(src.roots, root{fsys: os.DirFS(dir)})
+	}
+
+	return src
+}
+
+// fsSource returns the source for a file system. The scripts are named by their path inside the file system.
+func fsSource(fsys fs.FS) source {
+	return source{roots: []root{{fsys: fsys}}}
+}
+
+// script represents a single sql script of a source or a registered migration written in Go.
+type script struct {
+	fsys       fs.FS
+	file       string
+	name       string
+	migration  *migration
+	repeatable bool
+}
+
+// scan returns the versioned scripts of all roots merged with the migrations written in Go in ascending order,
+// followed by the repeatable scripts. The order is given by the versions, see sqlfile.SortFiles().
+func (src source) scan(migrations []*migration, opts sqlfile.ScanOptions) ([]*script, error) {
+	if len(src.roots) == 0 {
+		return nil, fmt.Errorf("%w: no path provided", sqlfile.ErrScanFiles)
+	}
+
+	byName := make(map[string]*script)
+	versioned := make([]string, 0)
+	repeatables := make([]string, 0)
+
+	for _, r := range src.roots {
+		files, err := sqlfile.ScanFSWithOptions(r.fsys, ".", opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, f := range files {
+			sc := &script{fsys: r.fsys, file: f, name: f}
+			if byName[sc.name] != nil {
+				return nil, fmt.Errorf("%w: %s exists in several folders", sqlfile.ErrScanFiles, sc.name)
+			}
+
+			sc.repeatable, err = sqlfile.IsRepeatableFS(r.fsys, f)
+			if err != nil {
+				return nil, err
+			}
+
+			byName[sc.name] = sc
+
+			if sc.repeatable {
+				repeatables = append(repeatables, sc.name)
+			} else {
+				versioned = append(versioned, sc.name)
+			}
+		}
+	}
+
+	for _, m := range migrations {
+		sc := &script{file: m.name, name: m.name, migration: m}
+		if byName[sc.name] != nil {
+			return nil, fmt.Errorf("%w: %s conflicts with a sql script", ErrInvalidMigration, m.name)
+		}
+
+		byName[sc.name] = sc
+		versioned = append(versioned, sc.name)
+	}
+
+	if err := sqlfile.SortFiles(versioned); err != nil {
+		return nil, err
+	}
+
+	sqlfile.SortRepeatables(repeatables)
+
+	scripts := make([]*script, 0, len(byName))
+	for _, name := range append(versioned, repeatables...) {
+		scripts = append(scripts, byName[name])
+	}
+
+	return scripts, nil
+}
+
+// scanReverse returns the scripts of the source in descending order.
+func (src source) scanReverse(migrations []*migration, opts sqlfile.ScanOptions) ([]*script, error) {
+	scripts, err := src.scan(migrations, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(scripts)-1; i < j; i, j = i+1, j-1 {
+		scripts[i], scripts[j] = scripts[j], scripts[i]
+	}
+
+	return scripts, nil
+}
+
+// read returns the statements of the script for the given command split in the same way as initdb.InitDB does before
+// executing them, each followed by a semicolon.
+func (sc *script) read(command string, opts sqlfile.SplitOptions) (string, error) {
+	if sc.migration != nil {
+		return "-- go migration", nil
+	}
+
+	statements, err := sqlfile.ReadStatementsFSWithOptions(sc.fsys, sc.file, command, opts)
+	if err != nil {
+		return "", err
+	}
+
+	lines := make([]string, 0, len(statements))
+	for _, statement := range statements {
+		lines = append(lines, statement.SQL+";")
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// checksum returns the checksum of the script, see sqlfile.Checksum(). Migrations written in Go have no checksum.
+func (sc *script) checksum() (string, error) {
+	if sc.migration != nil {
+		return "", nil
+	}
+
+	return sqlfile.ChecksumFS(sc.fsys, sc.file)
+}
+
+// relativeNames renames the entries recorded with the full path of a script by former versions to the name of the
+// script, see store.SchemaScript.Matches(). Entries of versioned scripts moved to another sub folder are renamed to
+// their new name, see moved(). The entries are only changed in memory, it returns the new names by the old ones to
+// store them.
+func relativeNames(scripts []*script, executedScripts store.SchemaScriptCollection) map[string]string {
+	renames := make(map[string]string)
+	known := make(map[string]bool)
+	recorded := make(map[string]bool)
+
+	for _, sc := range scripts {
+		known[sc.name] = true
+	}
+
+	for _, e := range executedScripts {
+		recorded[e.ScriptName] = true
+	}
+
+	for _, e := range executedScripts {
+		if known[e.ScriptName] {
+			continue
+		}
+
+		if name, ok := renames[e.ScriptName]; ok {
+			e.ScriptName = name
+			continue
+		}
+
+		sc := matching(e, scripts)
+		if sc == nil {
+			sc = moved(e, scripts, recorded)
+		}
+
+		if sc != nil {
+			renames[e.ScriptName] = sc.name
+			e.ScriptName = sc.name
+		}
+	}
+
+	return renames
+}
+
+// matching returns the script the entry belongs to, see store.SchemaScript.Matches(), or nil if there is none.
+func matching(e *store.SchemaScript, scripts []*script) *script {
+	for _, sc := range scripts {
+		if e.Matches(sc.name) {
+			return sc
+		}
+	}
+
+	return nil
+}
+
+// moved returns the versioned script with the same file name (and so the same version) as the entry, e.g. after
+// moving it to another sub folder. It returns nil if there is none, several ones or the script is recorded already.
+func moved(e *store.SchemaScript, scripts []*script, recorded map[string]bool) *script {
+	var found *script
+
+	for _, sc := range scripts {
+		if sc.repeatable || sc.migration != nil || recorded[sc.name] || path.Base(sc.name) != path.Base(e.ScriptName) {
+			continue
+		}
+
+		if found != nil {
+			return nil
+		}
+
+		found = sc
+	}
+
+	return found
+}
+
+// pending returns true if the script needs to be applied: a versioned script if it was never applied successfully,
+// a repeatable script also if its checksum differs from the last successful run.
+func (sc *script) pending(executedScripts store.SchemaScriptCollection) (bool, error) {
+	applied := executedScripts.LastSuccess(sc.name)
+	if applied == nil || !sc.repeatable {
+		return applied == nil, nil
+	}
+
+	checksum, err := sc.checksum()
+	if err != nil {
+		return false, err
+	}
+
+	return checksum != applied.Checksum, nil
+}
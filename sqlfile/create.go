@@ -0,0 +1,123 @@
+package sqlfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// SchemeDetect detects the numbering scheme by the existing files. It falls back to SchemeSequence.
+	SchemeDetect = ""
+
+	// SchemeSequence prefixes files with the next sequence number like 001, 002, 003, ...
+	SchemeSequence = "sequence"
+
+	// SchemeTimestamp prefixes files with the current UTC timestamp like 20190224153000
+	SchemeTimestamp = "timestamp"
+
+	timestampFormat = "20060102150405"
+	timestampWidth  = 8 // a prefix with at least 8 digits (yyyymmdd) is treated as timestamp
+	sequenceWidth   = 3
+)
+
+var (
+	// ErrCreateFile is used if a new file can't be created
+	ErrCreateFile = errors.New("create file failed")
+
+	skeleton = []byte(prefix + " " + CommandUpgrade + "\n\n\n" + prefix + " " + CommandDowngrade + "\n\n")
+)
+
+// Create writes a new file to dir containing an empty up and down section and returns its name (including path). The
+// name consists of a prefix given by the numbering scheme and the slugified description, e.g. "004_add_users.sql".
+// With SchemeDetect the scheme of the existing files is used.
+func Create(dir string, description string, scheme string) (string, error) {
+	slug := slugify(description)
+	if slug == "" {
+		return "", fmt.Errorf("%w: no description provided", ErrCreateFile)
+	}
+
+	files, err := Scan(dir)
+	if err != nil {
+		return "", err
+	}
+
+	last, width := lastNumber(files)
+
+	if scheme == SchemeDetect {
+		scheme = SchemeSequence
+		if width >= timestampWidth {
+			scheme = SchemeTimestamp
+		}
+	}
+
+	var number string
+
+	switch scheme {
+	case SchemeSequence:
+		number = fmt.Sprintf("%0*d", max(width, sequenceWidth), last+1)
+	case SchemeTimestamp:
+		number = time.Now().UTC().Format(timestampFormat)
+		if n, _ := strconv.ParseUint(number, 10, 64); n <= last {
+			number = strconv.FormatUint(last+1, 10)
+		}
+	default:
+		return "", fmt.Errorf("%w: unknown numbering scheme %s", ErrCreateFile, scheme)
+	}
+
+	fileName := filepath.Join(dir, number+"_"+slug+".sql")
+
+	f, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644) // nolint: gosec
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrCreateFile, err)
+	}
+
+	if _, err = f.Write(skeleton); err != nil {
+		_ = f.Close()
+		return "", fmt.Errorf("%w: %s", ErrCreateFile, err)
+	}
+
+	return fileName, f.Close()
+}
+
+// lastNumber returns the highest numeric prefix of the files and the number of its digits.
+func lastNumber(files []string) (uint64, int) {
+	var (
+		last  uint64
+		width int
+	)
+
+	for _, f := range files {
+		base := filepath.Base(f)
+
+		digits := strings.IndexFunc(base, func(r rune) bool {
+			return !unicode.IsDigit(r)
+		})
+		if digits <= 0 {
+			continue
+		}
+
+		n, err := strconv.ParseUint(base[:digits], 10, 64)
+		if err != nil || n < last {
+			continue
+		}
+
+		last, width = n, digits
+	}
+
+	return last, width
+}
+
+// slugify returns the description in lower case with words separated by underscore.
+func slugify(description string) string {
+	words := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return strings.Join(words, "_")
+}
@@ -0,0 +1,132 @@
+package sqlfile_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/rebel-l/schema/sqlfile"
+)
+
+func TestCreate_Happy(t *testing.T) {
+	testCases := []struct {
+		name     string
+		existing []string
+		scheme   string
+		expected string
+	}{
+		{
+			name:     "empty folder",
+			expected: `^001_add_users_table\.sql$`,
+		},
+		{
+			name:     "next sequence",
+			existing: []string{"001_first.sql", "004_second.sql", "readme.txt"},
+			expected: `^005_add_users_table\.sql$`,
+		},
+		{
+			name:     "sequence keeps width",
+			existing: []string{"00009_first.sql"},
+			expected: `^00010_add_users_table\.sql$`,
+		},
+		{
+			name:     "detect timestamp",
+			existing: []string{"20190224_first.sql"},
+			expected: `^20\d{12}_add_users_table\.sql$`,
+		},
+		{
+			name:     "explicit timestamp",
+			existing: []string{"001_first.sql"},
+			scheme:   sqlfile.SchemeTimestamp,
+			expected: `^20\d{12}_add_users_table\.sql$`,
+		},
+		{
+			name:     "timestamp after existing one in the future",
+			existing: []string{"99990101000000_first.sql"},
+			expected: `^99990101000001_add_users_table\.sql$`,
+		},
+		{
+			name:     "explicit sequence",
+			existing: []string{"20190224_first.sql"},
+			scheme:   sqlfile.SchemeSequence,
+			expected: `^20190225_add_users_table\.sql$`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			for _, f := range testCase.existing {
+				if err := os.WriteFile(filepath.Join(dir, f), []byte("-- up\n"), 0600); err != nil {
+					t.Fatalf("failed to prepare file: %s", err)
+				}
+			}
+
+			fileName, err := sqlfile.Create(dir, "Add users-table!", testCase.scheme)
+			if err != nil {
+				t.Fatalf("Expected no error but got %s", err)
+			}
+
+			if filepath.Dir(fileName) != dir {
+				t.Errorf("Expected file in %s but got %s", dir, fileName)
+			}
+
+			if !regexp.MustCompile(testCase.expected).MatchString(filepath.Base(fileName)) {
+				t.Errorf("Expected file name matching %s but got %s", testCase.expected, filepath.Base(fileName))
+			}
+
+			content, err := os.ReadFile(fileName)
+			if err != nil {
+				t.Fatalf("failed to read created file: %s", err)
+			}
+
+			if string(content) != "-- up\n\n\n-- down\n\n" {
+				t.Errorf("Expected skeleton with up and down section but got %q", content)
+			}
+		})
+	}
+}
+
+func TestCreate_Unhappy(t *testing.T) {
+	testCases := []struct {
+		name        string
+		dir         string
+		description string
+		scheme      string
+		expected    error
+	}{
+		{
+			name:        "no description",
+			dir:         t.TempDir(),
+			description: " - ",
+			expected:    sqlfile.ErrCreateFile,
+		},
+		{
+			name:        "unknown scheme",
+			dir:         t.TempDir(),
+			description: "something",
+			scheme:      "alphabetical",
+			expected:    sqlfile.ErrCreateFile,
+		},
+		{
+			name:        "folder not existing",
+			dir:         "./testdata/not_existing",
+			description: "something",
+			expected:    sqlfile.ErrScanFiles,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := sqlfile.Create(testCase.dir, testCase.description, testCase.scheme)
+			if !errors.Is(err, testCase.expected) {
+				t.Errorf("Expected error %s but got %v", testCase.expected, err)
+			}
+		})
+	}
+}
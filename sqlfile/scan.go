@@ -0,0 +1,191 @@
+package sqlfile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+var (
+	// ErrDuplicateVersion is used if several scripts have the same version
+	ErrDuplicateVersion = errors.New("duplicate script version")
+)
+
+// ScanOptions configures which files are found by ScanFSWithOptions.
+type ScanOptions struct {
+	// Recursive includes the files of sub folders.
+	Recursive bool
+
+	// Include contains glob patterns (see path.Match) a file must match to be found. A pattern containing a slash is
+	// matched against the path relative to the scanned directory, otherwise against the file name. Empty means all.
+	Include []string
+
+	// Exclude contains glob patterns for files and sub folders to skip, matched in the same way as Include.
+	Exclude []string
+}
+
+// ScanWithOptions does the same as Scan but considers the given options.
+func ScanWithOptions(dir string, opts ScanOptions) ([]string, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("%w: no path provided", ErrScanFiles)
+	}
+
+	files, err := ScanFSWithOptions(os.DirFS(dir), ".", opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, f := range files {
+		files[i] = dir + "/" + f
+	}
+
+	return files, nil
+}
+
+// ScanFSWithOptions does the same as ScanFS but considers the given options. The files are ordered by their version,
+// see SortFiles(), followed by the repeatable files ordered by name, see IsRepeatable().
+func ScanFSWithOptions(fsys fs.FS, dir string, opts ScanOptions) ([]string, error) {
+	for _, pattern := range append(append([]string{}, opts.Include...), opts.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%w: pattern %q: %s", ErrScanFiles, pattern, err)
+		}
+	}
+
+	files, err := scanDir(fsys, dir, ".", opts)
+	if err != nil {
+		return nil, err
+	}
+
+	versioned := make([]string, 0, len(files))
+	repeatables := make([]string, 0)
+
+	for _, f := range files {
+		repeatable, err := IsRepeatableFS(fsys, f)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrScanFiles, err)
+		}
+
+		if repeatable {
+			repeatables = append(repeatables, f)
+		} else {
+			versioned = append(versioned, f)
+		}
+	}
+
+	if err = SortFiles(versioned); err != nil {
+		return nil, err
+	}
+
+	SortRepeatables(repeatables)
+
+	return append(versioned, repeatables...), nil
+}
+
+func scanDir(fsys fs.FS, root string, rel string, opts ScanOptions) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, path.Join(root, rel))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrScanFiles, err)
+	}
+
+	files := make([]string, 0)
+
+	for _, v := range entries {
+		name := path.Join(rel, v.Name())
+
+		if matchAny(opts.Exclude, name) {
+			continue
+		}
+
+		if v.IsDir() {
+			if !opts.Recursive {
+				continue
+			}
+
+			sub, err := scanDir(fsys, root, name, opts)
+			if err != nil {
+				return nil, err
+			}
+
+			files = append(files, sub...)
+
+			continue
+		}
+
+		if path.Ext(v.Name()) != ".sql" || len(opts.Include) > 0 && !matchAny(opts.Include, name) {
+			continue
+		}
+
+		info, err := v.Info()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrScanFiles, err)
+		}
+
+		if info.Size() == 0 {
+			continue
+		}
+
+		files = append(files, path.Join(root, name))
+	}
+
+	return files, nil
+}
+
+// matchAny returns true if the name matches one of the patterns. Patterns without slash are matched against the
+// last element of name.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		subject := name
+		if !strings.Contains(pattern, "/") {
+			subject = path.Base(name)
+		}
+
+		if ok, _ := path.Match(pattern, subject); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SortFiles orders the files by their version, see ParseVersion(). It returns ErrInvalidVersion if the version of a
+// file can't be parsed and ErrDuplicateVersion if several files have the same version, even in different directories.
+func SortFiles(files []string) error {
+	versions := make(map[string]Version, len(files))
+
+	for _, f := range files {
+		version, err := ParseVersion(f)
+		if err != nil {
+			return err
+		}
+
+		versions[f] = version
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return versions[files[i]].Compare(versions[files[j]]) < 0
+	})
+
+	for i := 1; i < len(files); i++ {
+		if versions[files[i-1]].Compare(versions[files[i]]) == 0 {
+			return fmt.Errorf("%w: %s and %s have version %s", ErrDuplicateVersion, files[i-1], files[i], versions[files[i]])
+		}
+	}
+
+	return nil
+}
+
+// SortRepeatables orders repeatable files, which have no version, by their file name independent of their directory.
+func SortRepeatables(files []string) {
+	sort.SliceStable(files, func(i, j int) bool {
+		baseI, baseJ := path.Base(files[i]), path.Base(files[j])
+		if baseI != baseJ {
+			return baseI < baseJ
+		}
+
+		return files[i] < files[j]
+	})
+}
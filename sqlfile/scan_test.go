@@ -0,0 +1,172 @@
+package sqlfile_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/rebel-l/schema/sqlfile"
+)
+
+func scanFS() fstest.MapFS {
+	content := []byte("-- up\nSELECT 1;\n")
+
+	return fstest.MapFS{
+		"migrations/003_root.sql":            {Data: content},
+		"migrations/billing/001_invoice.sql": {Data: content},
+		"migrations/billing/notes.txt":       {Data: content},
+		"migrations/users/002_users.sql":     {Data: content},
+		"migrations/users/2020/004_old.sql":  {Data: content},
+		"migrations/legacy/000_legacy.sql":   {Data: content},
+		"migrations/users/005_empty.sql":     {Data: []byte{}},
+	}
+}
+
+func TestScanFSWithOptions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     sqlfile.ScanOptions
+		expected []string
+	}{
+		{
+			name:     "not recursive",
+			expected: []string{"migrations/003_root.sql"},
+		},
+		{
+			name: "recursive",
+			opts: sqlfile.ScanOptions{Recursive: true},
+			expected: []string{
+				"migrations/legacy/000_legacy.sql",
+				"migrations/billing/001_invoice.sql",
+				"migrations/users/002_users.sql",
+				"migrations/003_root.sql",
+				"migrations/users/2020/004_old.sql",
+			},
+		},
+		{
+			name: "exclude folder and file",
+			opts: sqlfile.ScanOptions{Recursive: true, Exclude: []string{"legacy", "users/2020/*"}},
+			expected: []string{
+				"migrations/billing/001_invoice.sql",
+				"migrations/users/002_users.sql",
+				"migrations/003_root.sql",
+			},
+		},
+		{
+			name: "include",
+			opts: sqlfile.ScanOptions{Recursive: true, Include: []string{"billing/*.sql", "*_root.sql"}},
+			expected: []string{
+				"migrations/billing/001_invoice.sql",
+				"migrations/003_root.sql",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			actual, err := sqlfile.ScanFSWithOptions(scanFS(), "migrations", testCase.opts)
+			if err != nil {
+				t.Fatalf("Expected no error but got %s", err)
+			}
+
+			if !reflect.DeepEqual(testCase.expected, actual) {
+				t.Errorf("Expected %v but got %v", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestScanFSWithOptions_Unhappy(t *testing.T) {
+	duplicate := scanFS()
+	duplicate["migrations/users/001_users.sql"] = &fstest.MapFile{Data: []byte("-- up\nSELECT 1;\n")}
+
+	testCases := []struct {
+		name     string
+		fsys     fstest.MapFS
+		opts     sqlfile.ScanOptions
+		expected error
+	}{
+		{
+			name:     "duplicate version in different folders",
+			fsys:     duplicate,
+			opts:     sqlfile.ScanOptions{Recursive: true},
+			expected: sqlfile.ErrDuplicateVersion,
+		},
+		{
+			name:     "duplicate version in same folder",
+			fsys:     fstest.MapFS{"migrations/1_a.sql": {Data: []byte("x")}, "migrations/001_b.sql": {Data: []byte("x")}},
+			expected: sqlfile.ErrDuplicateVersion,
+		},
+		{
+			name:     "no version",
+			fsys:     fstest.MapFS{"migrations/users.sql": {Data: []byte("x")}},
+			expected: sqlfile.ErrInvalidVersion,
+		},
+		{
+			name:     "invalid pattern",
+			fsys:     scanFS(),
+			opts:     sqlfile.ScanOptions{Include: []string{"[a-"}},
+			expected: sqlfile.ErrScanFiles,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := sqlfile.ScanFSWithOptions(testCase.fsys, "migrations", testCase.opts)
+			if !errors.Is(err, testCase.expected) {
+				t.Errorf("Expected error %s but got %v", testCase.expected, err)
+			}
+		})
+	}
+}
+
+func TestScanWithOptions(t *testing.T) {
+	actual, err := sqlfile.ScanWithOptions("./testdata/case1", sqlfile.ScanOptions{Exclude: []string{"004_*"}})
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	expected := []string{"./testdata/case1/001_with_content.sql"}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	if _, err = sqlfile.ScanWithOptions("", sqlfile.ScanOptions{}); !errors.Is(err, sqlfile.ErrScanFiles) {
+		t.Errorf("Expected error %s but got %v", sqlfile.ErrScanFiles, err)
+	}
+}
+
+func TestScanFS_NaturalOrder(t *testing.T) {
+	content := []byte("-- up\nSELECT 1;\n")
+	fsys := fstest.MapFS{
+		"10.sql":       {Data: content},
+		"9.sql":        {Data: content},
+		"1.10_y.sql":   {Data: content},
+		"1.2_x.sql":    {Data: content},
+		"R__views.sql": {Data: content},
+		"views.sql":    {Data: []byte("-- repeatable\n-- up\nSELECT 1;\n")},
+	}
+
+	expected := []string{"1.2_x.sql", "1.10_y.sql", "9.sql", "10.sql", "R__views.sql", "views.sql"}
+
+	actual, err := sqlfile.ScanFS(fsys, ".")
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v but got %v", expected, actual)
+	}
+
+	actual, err = sqlfile.ScanReverseFS(fsys, ".")
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if expected[0] != actual[len(actual)-1] || expected[len(expected)-1] != actual[0] {
+		t.Errorf("Expected reverse order of %v but got %v", expected, actual)
+	}
+}
@@ -3,12 +3,15 @@ package sqlfile
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
-	"sort"
 	"strings"
 )
 
@@ -18,63 +21,91 @@ const (
 
 	// CommandDowngrade represents the string to get the downgrade statements
 	CommandDowngrade = "down"
-	prefix           = "--"
+
+	// DirectiveNoTransaction marks a script which must not be executed inside a transaction
+	DirectiveNoTransaction = "no-transaction"
+
+	// DirectiveRepeatable marks a script which is applied again whenever its content changes, see IsRepeatable
+	DirectiveRepeatable = "repeatable"
+
+	// PrefixRepeatable marks a script by its file name as repeatable, e.g. "R__views.sql"
+	PrefixRepeatable = "R__"
+
+	prefix = "--"
 )
 
 var (
 	// ErrScanFiles is used if scanning a file fails
 	ErrScanFiles = errors.New("scan files failed")
+
+	directives = map[string]bool{
+		DirectiveNoTransaction: true,
+		DirectiveRepeatable:    true,
+	}
 )
 
-// Scan returns a sorted (asc) list of files (including path) ending with .sql
-// and file size bigger than zero. It excludes directories.
+// Scan returns a list of files (including path) ending with .sql and file size bigger than zero sorted (asc) by their
+// version, see SortFiles(). Repeatable files follow at the end. It excludes directories.
 func Scan(path string) ([]string, error) {
-	files, err := ioutil.ReadDir(path)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %s", ErrScanFiles, err)
+	if path == "" {
+		return nil, fmt.Errorf("%w: no path provided", ErrScanFiles)
 	}
 
-	cleaned := make([]string, 0)
-
-	for _, v := range files {
-		if v.IsDir() {
-			continue
-		}
-
-		if filepath.Ext(v.Name()) != ".sql" {
-			continue
-		}
-
-		if v.Size() == 0 {
-			continue
-		}
+	files, err := ScanFS(os.DirFS(path), ".")
+	if err != nil {
+		return nil, err
+	}
 
-		cleaned = append(cleaned, path+"/"+v.Name())
+	for i, f := range files {
+		files[i] = path + "/" + f
 	}
 
-	return cleaned, nil
+	return files, nil
+}
+
+// ScanFS does the same as Scan but for the directory dir of the given file system, e.g. an embed.FS.
+// The returned file names are relative to the root of the file system.
+func ScanFS(fsys fs.FS, dir string) ([]string, error) {
+	return ScanFSWithOptions(fsys, dir, ScanOptions{})
 }
 
 // ScanReverse does the same as Scan but returns the filenames in reverse order.
 func ScanReverse(path string) ([]string, error) {
-	var files sort.StringSlice
+	files, err := Scan(path)
+	if err != nil {
+		return nil, err
+	}
 
-	var err error
+	return reverse(files), nil
+}
 
-	files, err = Scan(path)
+// ScanReverseFS does the same as ScanFS but returns the filenames in reverse order.
+func ScanReverseFS(fsys fs.FS, dir string) ([]string, error) {
+	files, err := ScanFS(fsys, dir)
 	if err != nil {
 		return nil, err
 	}
 
-	sort.Sort(sort.Reverse(files))
+	return reverse(files), nil
+}
 
-	return files, nil
+func reverse(files []string) []string {
+	for i, j := 0, len(files)-1; i < j; i, j = i+1, j-1 {
+		files[i], files[j] = files[j], files[i]
+	}
+
+	return files
 }
 
-// Read returns the content of a file for the given command.
+// Read returns the content of a file for the given command. The content is normalized: lines are trimmed, empty
+// lines and comment lines are removed. Use ReadStatements to get the statements for execution.
 func Read(fileName string, command string) (string, error) {
-	file, err := os.Open(filepath.Clean(fileName))
+	return ReadFS(os.DirFS(filepath.Dir(fileName)), filepath.Base(fileName), command)
+}
 
+// ReadFS does the same as Read but for a file of the given file system.
+func ReadFS(fsys fs.FS, fileName string, command string) (string, error) {
+	file, err := fsys.Open(fileName)
 	if err != nil {
 		return "", err
 	}
@@ -97,14 +128,11 @@ func Read(fileName string, command string) (string, error) {
 			continue
 		}
 
-		// check line if it contains commands
+		// a comment line either starts a section or is skipped, directives and other comments don't end a section
 		if strings.HasPrefix(line, prefix) {
 			section := strings.ToLower(strings.TrimSpace(line[len(prefix):]))
-			switch section {
-			case command:
-				recording = true
-			default:
-				recording = false
+			if section == CommandUpgrade || section == CommandDowngrade {
+				recording = section == command
 			}
 
 			continue
@@ -118,3 +146,73 @@ func Read(fileName string, command string) (string, error) {
 
 	return buffer, nil
 }
+
+// HasDirective returns true if the file contains the given directive as SQL comment, e.g. "-- no-transaction".
+func HasDirective(fileName string, directive string) (bool, error) {
+	return HasDirectiveFS(os.DirFS(filepath.Dir(fileName)), filepath.Base(fileName), directive)
+}
+
+// HasDirectiveFS does the same as HasDirective but for a file of the given file system.
+func HasDirectiveFS(fsys fs.FS, fileName string, directive string) (bool, error) {
+	file, err := fsys.Open(fileName)
+	if err != nil {
+		return false, err
+	}
+
+	defer func() {
+		err = file.Close()
+	}()
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+
+		if strings.ToLower(strings.TrimSpace(line[len(prefix):])) == directive {
+			return true, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
+
+// IsRepeatable returns true if the file is a repeatable script, e.g. for views, stored procedures or triggers. A script
+// is repeatable if its file name starts with PrefixRepeatable or it contains the directive "-- repeatable".
+func IsRepeatable(fileName string) (bool, error) {
+	return IsRepeatableFS(os.DirFS(filepath.Dir(fileName)), filepath.Base(fileName))
+}
+
+// IsRepeatableFS does the same as IsRepeatable but for a file of the given file system.
+func IsRepeatableFS(fsys fs.FS, fileName string) (bool, error) {
+	if strings.HasPrefix(path.Base(fileName), PrefixRepeatable) {
+		return true, nil
+	}
+
+	return HasDirectiveFS(fsys, fileName, DirectiveRepeatable)
+}
+
+// Checksum returns the SHA-256 checksum (hex encoded) of the upgrade and downgrade statements of a file. It is based
+// on the normalized content returned by Read, so changes in indentation, empty lines or comment lines don't change the
+// checksum.
+func Checksum(fileName string) (string, error) {
+	return ChecksumFS(os.DirFS(filepath.Dir(fileName)), filepath.Base(fileName))
+}
+
+// ChecksumFS does the same as Checksum but for a file of the given file system.
+func ChecksumFS(fsys fs.FS, fileName string) (string, error) {
+	h := sha256.New()
+
+	for _, command := range []string{CommandUpgrade, CommandDowngrade} {
+		content, err := ReadFS(fsys, fileName, command)
+		if err != nil {
+			return "", err
+		}
+
+		_, _ = io.WriteString(h, prefix+" "+command+content+"\n")
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
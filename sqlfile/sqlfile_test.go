@@ -1,7 +1,9 @@
 package sqlfile_test
 
 import (
+	"errors"
 	"testing"
+	"testing/fstest"
 
 	"github.com/rebel-l/go-utils/array"
 	"github.com/rebel-l/schema/sqlfile"
@@ -145,3 +147,238 @@ func TestReadUnhappy(t *testing.T) {
 		t.Errorf("Expected that content is empty on error but got %s", content)
 	}
 }
+
+func TestReadHappy_IgnoresDirectives(t *testing.T) {
+	expected := `
+CREATE INDEX idx_test ON test (id);
+CREATE INDEX idx_another ON another (id);`
+
+	actual, err := sqlfile.Read("./testdata/Directive/no_transaction.sql", sqlfile.CommandUpgrade)
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if expected != actual {
+		t.Errorf("Expected file content '%s' but got '%s'", expected, actual)
+	}
+}
+
+func TestReadHappy_IgnoresComments(t *testing.T) {
+	expected := `
+CREATE TABLE IF NOT EXISTS test (
+id INTEGER
+);
+CREATE TABLE IF NOT EXISTS another (
+id INTEGER
+);`
+
+	actual, err := sqlfile.Read("./testdata/Checksum/commented.sql", sqlfile.CommandUpgrade)
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if expected != actual {
+		t.Errorf("Expected file content '%s' but got '%s'", expected, actual)
+	}
+}
+
+func TestHasDirective(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fileName string
+		expected bool
+	}{
+		{
+			name:     "with directive",
+			fileName: "./testdata/Directive/no_transaction.sql",
+			expected: true,
+		},
+		{
+			name:     "without directive",
+			fileName: "./testdata/Read/test.sql",
+			expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		fileName := testCase.fileName
+		expected := testCase.expected
+		t.Run(testCase.name, func(t *testing.T) {
+			actual, err := sqlfile.HasDirective(fileName, sqlfile.DirectiveNoTransaction)
+			if err != nil {
+				t.Fatalf("Expected no error but got %s", err)
+			}
+
+			if expected != actual {
+				t.Errorf("Expected %t but got %t", expected, actual)
+			}
+		})
+	}
+}
+
+func TestHasDirectiveUnhappy(t *testing.T) {
+	if _, err := sqlfile.HasDirective("not_exist.sql", sqlfile.DirectiveNoTransaction); err == nil {
+		t.Error("Expected that error is thrown for not existing file")
+	}
+}
+
+func TestIsRepeatable(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fileName string
+		expected bool
+	}{
+		{
+			name:     "with directive",
+			fileName: "./testdata/Directive/repeatable.sql",
+			expected: true,
+		},
+		{
+			name:     "with prefix",
+			fileName: "./testdata/Directive/R__views.sql",
+			expected: true,
+		},
+		{
+			name:     "versioned",
+			fileName: "./testdata/Directive/no_transaction.sql",
+			expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		fileName := testCase.fileName
+		expected := testCase.expected
+		t.Run(testCase.name, func(t *testing.T) {
+			actual, err := sqlfile.IsRepeatable(fileName)
+			if err != nil {
+				t.Fatalf("Expected no error but got %s", err)
+			}
+
+			if expected != actual {
+				t.Errorf("Expected %t but got %t", expected, actual)
+			}
+		})
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	expected, err := sqlfile.Checksum("./testdata/Read/test.sql")
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if len(expected) != 64 {
+		t.Errorf("Expected hex encoded SHA-256 checksum but got '%s'", expected)
+	}
+
+	testCases := []struct {
+		name     string
+		fileName string
+		equal    bool
+	}{
+		{
+			name:     "same statements with different formatting",
+			fileName: "./testdata/Checksum/formatted.sql",
+			equal:    true,
+		},
+		{
+			name:     "modified statements",
+			fileName: "./testdata/Checksum/modified.sql",
+			equal:    false,
+		},
+		{
+			name:     "same statements with comment lines",
+			fileName: "./testdata/Checksum/commented.sql",
+			equal:    true,
+		},
+		{
+			name:     "modified statements after comment lines",
+			fileName: "./testdata/Checksum/commented_modified.sql",
+			equal:    false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		fileName := testCase.fileName
+		equal := testCase.equal
+		t.Run(testCase.name, func(t *testing.T) {
+			actual, err := sqlfile.Checksum(fileName)
+			if err != nil {
+				t.Fatalf("Expected no error but got %s", err)
+			}
+
+			if equal != (expected == actual) {
+				t.Errorf("Expected checksums to be equal: %t, got '%s' and '%s'", equal, expected, actual)
+			}
+		})
+	}
+}
+
+func TestChecksumUnhappy(t *testing.T) {
+	if _, err := sqlfile.Checksum("not_exist.sql"); err == nil {
+		t.Error("Expected that error is thrown for not existing file")
+	}
+}
+
+func TestScanFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"migrations/002.sql":         {Data: []byte("-- up\nSELECT 2;")},
+		"migrations/001.sql":         {Data: []byte("-- up\nSELECT 1;")},
+		"migrations/003_empty.sql":   {Data: []byte{}},
+		"migrations/004.txt":         {Data: []byte("ignored")},
+		"migrations/sub/005_sub.sql": {Data: []byte("-- up\nSELECT 5;")},
+	}
+
+	actual, err := sqlfile.ScanFS(fsys, "migrations")
+	if err != nil {
+		t.Fatalf("scan shouldn't cause error: %s", err)
+	}
+
+	expected := []string{"migrations/001.sql", "migrations/002.sql"}
+	if !array.StringArrayEquals(expected, actual) {
+		t.Errorf("Expected %#v but got %#v", expected, actual)
+	}
+
+	actual, err = sqlfile.ScanReverseFS(fsys, "migrations")
+	if err != nil {
+		t.Fatalf("scan shouldn't cause error: %s", err)
+	}
+
+	expected = []string{"migrations/002.sql", "migrations/001.sql"}
+	if !array.StringArrayEquals(expected, actual) {
+		t.Errorf("Expected %#v but got %#v", expected, actual)
+	}
+
+	if _, err = sqlfile.ScanFS(fsys, "not_existing"); !errors.Is(err, sqlfile.ErrScanFiles) {
+		t.Errorf("Expected error %s but got %v", sqlfile.ErrScanFiles, err)
+	}
+}
+
+func TestReadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"001.sql": {Data: []byte("-- no-transaction\n-- up\nCREATE TABLE a (id INTEGER);\n-- down\nDROP TABLE a;\n")},
+	}
+
+	actual, err := sqlfile.ReadFS(fsys, "001.sql", sqlfile.CommandDowngrade)
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if expected := "\nDROP TABLE a;"; expected != actual {
+		t.Errorf("Expected file content '%s' but got '%s'", expected, actual)
+	}
+
+	noTransaction, err := sqlfile.HasDirectiveFS(fsys, "001.sql", sqlfile.DirectiveNoTransaction)
+	if err != nil || !noTransaction {
+		t.Errorf("Expected directive is found but got %t, %v", noTransaction, err)
+	}
+
+	checksum, err := sqlfile.ChecksumFS(fsys, "001.sql")
+	if err != nil || checksum == "" {
+		t.Errorf("Expected checksum but got '%s', %v", checksum, err)
+	}
+
+	if _, err = sqlfile.ReadFS(fsys, "not_exist.sql", sqlfile.CommandUpgrade); err == nil {
+		t.Error("Expected that error is thrown for not existing file")
+	}
+}
@@ -0,0 +1,326 @@
+package sqlfile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultDelimiter = ";"
+	delimiterCommand = "delimiter"
+)
+
+var (
+	// ErrSyntax is used if a script can't be split into statements, e.g. because of an unterminated string literal
+	ErrSyntax = errors.New("invalid sql script")
+)
+
+// Statement represents a single SQL statement of a script.
+type Statement struct {
+	SQL  string
+	Line int
+}
+
+// ReadStatements returns the statements of a file for the given command. In contrast to Read it keeps the content
+// untouched and splits it into single statements, see Split().
+func ReadStatements(fileName string, command string) ([]*Statement, error) {
+	return ReadStatementsFS(os.DirFS(filepath.Dir(fileName)), filepath.Base(fileName), command)
+}
+
+// SplitOptions configures how scripts are split into statements by SplitWithOptions.
+type SplitOptions struct {
+	// BackslashEscapes treats a backslash inside of string literals as escape character like MySQL does, e.g.
+	// 'It\'s'. Otherwise only E'...' strings of PostgreSQL support backslash escapes.
+	BackslashEscapes bool
+}
+
+// ReadStatementsFS does the same as ReadStatements but for a file of the given file system.
+func ReadStatementsFS(fsys fs.FS, fileName string, command string) ([]*Statement, error) {
+	return ReadStatementsFSWithOptions(fsys, fileName, command, SplitOptions{})
+}
+
+// ReadStatementsFSWithOptions does the same as ReadStatementsFS but splits the content with the given options.
+func ReadStatementsFSWithOptions(fsys fs.FS, fileName string, command string, opts SplitOptions) ([]*Statement, error) {
+	content, err := fs.ReadFile(fsys, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	statements, err := SplitWithOptions(string(content), command, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+
+	return statements, nil
+}
+
+// Split returns the statements of the section for the given command. Statements are separated by semicolon which can
+// be changed by a line "DELIMITER //" like in the MySQL client. Delimiters inside of string literals ('...', E'...'),
+// quoted identifiers ("..." and `...`), comments (-- and /* */) and dollar quoted bodies ($$...$$, $body$...$body$)
+// are ignored. Each statement contains the number of the line it starts in.
+func Split(content string, command string) ([]*Statement, error) {
+	return SplitWithOptions(content, command, SplitOptions{})
+}
+
+// SplitWithOptions does the same as Split but with the given options, e.g. backslash escapes for MySQL.
+func SplitWithOptions(content string, command string, opts SplitOptions) ([]*Statement, error) {
+	s := &splitter{content: content, command: command, opts: opts, line: 1, delimiter: defaultDelimiter}
+	if err := s.split(); err != nil {
+		return nil, err
+	}
+
+	return s.statements, nil
+}
+
+type splitter struct {
+	content    string
+	command    string
+	opts       SplitOptions
+	pos        int
+	line       int
+	delimiter  string
+	recording  bool
+	buffer     strings.Builder
+	bufferLine int
+	hasSQL     bool
+	statements []*Statement
+}
+
+func (s *splitter) split() error {
+	for s.pos < len(s.content) {
+		if s.atLineStart() && s.control() {
+			continue
+		}
+
+		if !s.recording {
+			s.skipLine()
+			continue
+		}
+
+		if err := s.token(); err != nil {
+			return err
+		}
+	}
+
+	s.flush()
+
+	return nil
+}
+
+func (s *splitter) atLineStart() bool {
+	return s.pos == 0 || s.content[s.pos-1] == '\n'
+}
+
+// control handles lines marking a section, directives and delimiter commands. It returns true if the line was consumed.
+func (s *splitter) control() bool {
+	line := s.content[s.pos:]
+	if end := strings.IndexByte(line, '\n'); end >= 0 {
+		line = line[:end]
+	}
+
+	line = strings.TrimSpace(line)
+
+	if strings.HasPrefix(line, prefix) {
+		section := strings.ToLower(strings.TrimSpace(line[len(prefix):]))
+		if directives[section] {
+			s.skipLine()
+			return true
+		}
+
+		if section != CommandUpgrade && section != CommandDowngrade {
+			return false
+		}
+
+		s.flush()
+		s.recording = section == s.command
+		s.delimiter = defaultDelimiter
+		s.skipLine()
+
+		return true
+	}
+
+	fields := strings.Fields(line)
+	if !s.recording || s.hasSQL || len(fields) != 2 || strings.ToLower(fields[0]) != delimiterCommand {
+		return false
+	}
+
+	s.delimiter = fields[1]
+	s.skipLine()
+
+	return true
+}
+
+func (s *splitter) skipLine() {
+	end := strings.IndexByte(s.content[s.pos:], '\n')
+	if end < 0 {
+		s.pos = len(s.content)
+		return
+	}
+
+	s.pos += end + 1
+	s.line++
+}
+
+func (s *splitter) token() error {
+	rest := s.content[s.pos:]
+
+	switch {
+	case strings.HasPrefix(rest, s.delimiter):
+		s.pos += len(s.delimiter)
+		s.flush()
+	case strings.HasPrefix(rest, "--"):
+		end := strings.IndexByte(rest, '\n')
+		if end < 0 {
+			end = len(rest)
+		}
+
+		s.write(end, false)
+	case strings.HasPrefix(rest, "/*"):
+		end, err := s.blockComment(rest)
+		if err != nil {
+			return err
+		}
+
+		s.write(end, false)
+	case rest[0] == '\'' || rest[0] == '"' || rest[0] == '`':
+		end, err := s.quoted(rest)
+		if err != nil {
+			return err
+		}
+
+		s.write(end, true)
+	case rest[0] == '$':
+		end, err := s.dollarQuoted(rest)
+		if err != nil {
+			return err
+		}
+
+		s.write(end, true)
+	default:
+		s.write(1, rest[0] != ' ' && rest[0] != '\t' && rest[0] != '\n' && rest[0] != '\r')
+	}
+
+	return nil
+}
+
+// write adds the next n bytes to the current statement. Whitespace and comments before the first SQL token are dropped.
+func (s *splitter) write(n int, isSQL bool) {
+	text := s.content[s.pos : s.pos+n]
+
+	if isSQL && !s.hasSQL {
+		s.hasSQL = true
+		s.bufferLine = s.line
+	}
+
+	if s.hasSQL {
+		s.buffer.WriteString(text)
+	}
+
+	s.pos += n
+	s.line += strings.Count(text, "\n")
+}
+
+func (s *splitter) flush() {
+	if s.hasSQL {
+		s.statements = append(s.statements, &Statement{SQL: strings.TrimSpace(s.buffer.String()), Line: s.bufferLine})
+	}
+
+	s.buffer.Reset()
+	s.hasSQL = false
+}
+
+// blockComment returns the length of the (nested) block comment at the beginning of rest.
+func (s *splitter) blockComment(rest string) (int, error) {
+	depth := 0
+
+	for i := 0; i < len(rest)-1; i++ {
+		switch rest[i : i+2] {
+		case "/*":
+			depth++
+			i++
+		case "*/":
+			depth--
+			i++
+
+			if depth == 0 {
+				return i + 1, nil
+			}
+		}
+	}
+
+	return 0, s.unterminated("block comment")
+}
+
+// quoted returns the length of the string literal or quoted identifier at the beginning of rest. A quote is escaped by
+// doubling it, inside of E'...' strings also by a backslash. With backslash escapes, a backslash escapes inside of all
+// string literals ('...' and "..." like in MySQL), but not inside of quoted identifiers (`...`).
+func (s *splitter) quoted(rest string) (int, error) {
+	quote := rest[0]
+	backslash := quote == '\'' && s.escapePrefix() || s.opts.BackslashEscapes && quote != '`'
+
+	for i := 1; i < len(rest); i++ {
+		switch {
+		case backslash && rest[i] == '\\':
+			i++
+		case rest[i] != quote:
+			continue
+		case i+1 < len(rest) && rest[i+1] == quote:
+			i++
+		default:
+			return i + 1, nil
+		}
+	}
+
+	return 0, s.unterminated("quoted string")
+}
+
+// dollarQuoted returns the length of the dollar quoted body at the beginning of rest or 1 if rest doesn't start with a
+// dollar quote tag, e.g. for positional parameters like $1.
+func (s *splitter) dollarQuoted(rest string) (int, error) {
+	if s.pos > 0 && isIdentifierChar(s.content[s.pos-1]) {
+		return 1, nil
+	}
+
+	end := 1
+	for end < len(rest) && isIdentifierChar(rest[end]) && !(end == 1 && isDigit(rest[end])) {
+		end++
+	}
+
+	if end >= len(rest) || rest[end] != '$' {
+		return 1, nil
+	}
+
+	tag := rest[:end+1]
+
+	closing := strings.Index(rest[len(tag):], tag)
+	if closing < 0 {
+		return 0, s.unterminated("dollar quoted body " + tag)
+	}
+
+	return len(tag) + closing + len(tag), nil
+}
+
+// escapePrefix returns true if the string literal at the current position is prefixed by E like E'...'.
+func (s *splitter) escapePrefix() bool {
+	if s.pos == 0 || s.content[s.pos-1] != 'E' && s.content[s.pos-1] != 'e' {
+		return false
+	}
+
+	return s.pos == 1 || !isIdentifierChar(s.content[s.pos-2])
+}
+
+func (s *splitter) unterminated(what string) error {
+	return fmt.Errorf("%w: unterminated %s starting at line %d", ErrSyntax, what, s.line)
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
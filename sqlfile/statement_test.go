@@ -0,0 +1,206 @@
+package sqlfile_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/rebel-l/schema/sqlfile"
+)
+
+func TestSplit_Happy(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		command  string
+		expected []*sqlfile.Statement
+	}{
+		{
+			name:     "empty",
+			content:  "",
+			command:  sqlfile.CommandUpgrade,
+			expected: nil,
+		},
+		{
+			name: "sections",
+			content: "-- up\nCREATE TABLE a (id INTEGER);\nCREATE TABLE b (id INTEGER);\n\n" +
+				"-- down\nDROP TABLE b;\nDROP TABLE a;\n",
+			command: sqlfile.CommandDowngrade,
+			expected: []*sqlfile.Statement{
+				{SQL: "DROP TABLE b", Line: 6},
+				{SQL: "DROP TABLE a", Line: 7},
+			},
+		},
+		{
+			name:    "content before first section and directives are ignored",
+			content: "SELECT 0;\n-- no-transaction\n-- up\nSELECT 1;\n",
+			command: sqlfile.CommandUpgrade,
+			expected: []*sqlfile.Statement{
+				{SQL: "SELECT 1", Line: 4},
+			},
+		},
+		{
+			name:    "last statement without delimiter",
+			content: "-- up\nSELECT 1;\nSELECT 2\n-- down\nSELECT 3;",
+			command: sqlfile.CommandUpgrade,
+			expected: []*sqlfile.Statement{
+				{SQL: "SELECT 1", Line: 2},
+				{SQL: "SELECT 2", Line: 3},
+			},
+		},
+		{
+			name:    "multi line string literal",
+			content: "-- up\nINSERT INTO a VALUES ('x;\n  -- down\n''y''');\n",
+			command: sqlfile.CommandUpgrade,
+			expected: []*sqlfile.Statement{
+				{SQL: "INSERT INTO a VALUES ('x;\n  -- down\n''y''')", Line: 2},
+			},
+		},
+		{
+			name:    "escaped string literal",
+			content: "-- up\nSELECT E'it\\'s;', 'C:\\';\n",
+			command: sqlfile.CommandUpgrade,
+			expected: []*sqlfile.Statement{
+				{SQL: "SELECT E'it\\'s;', 'C:\\'", Line: 2},
+			},
+		},
+		{
+			name:    "quoted identifiers",
+			content: "-- up\nSELECT \"a;b\", `c;d` FROM t;\n",
+			command: sqlfile.CommandUpgrade,
+			expected: []*sqlfile.Statement{
+				{SQL: "SELECT \"a;b\", `c;d` FROM t", Line: 2},
+			},
+		},
+		{
+			name:    "comments",
+			content: "-- up\n-- leading comment;\n/* block;\n /* nested; */ */\nSELECT 1 -- trailing;\n;\n",
+			command: sqlfile.CommandUpgrade,
+			expected: []*sqlfile.Statement{
+				{SQL: "SELECT 1 -- trailing;", Line: 5},
+			},
+		},
+		{
+			name: "dollar quoted bodies",
+			content: "-- up\nCREATE FUNCTION f() RETURNS INTEGER AS $body$\n" +
+				"BEGIN\n  RETURN 1;\nEND;\n$body$ LANGUAGE plpgsql;\n" +
+				"DO $$ BEGIN PERFORM 1; END $$;\nSELECT $1, a$b$c FROM t;\n",
+			command: sqlfile.CommandUpgrade,
+			expected: []*sqlfile.Statement{
+				{SQL: "CREATE FUNCTION f() RETURNS INTEGER AS $body$\nBEGIN\n  RETURN 1;\nEND;\n$body$ LANGUAGE plpgsql", Line: 2},
+				{SQL: "DO $$ BEGIN PERFORM 1; END $$", Line: 7},
+				{SQL: "SELECT $1, a$b$c FROM t", Line: 8},
+			},
+		},
+		{
+			name: "custom delimiter",
+			content: "-- up\nDELIMITER //\nCREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW BEGIN\n  SET NEW.id = 1;\nEND//\n" +
+				"DELIMITER ;\nSELECT 1;\n-- down\nDROP TRIGGER t;\n",
+			command: sqlfile.CommandUpgrade,
+			expected: []*sqlfile.Statement{
+				{SQL: "CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW BEGIN\n  SET NEW.id = 1;\nEND", Line: 3},
+				{SQL: "SELECT 1", Line: 7},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			actual, err := sqlfile.Split(testCase.content, testCase.command)
+			if err != nil {
+				t.Fatalf("Expected no error but got %s", err)
+			}
+
+			if !reflect.DeepEqual(testCase.expected, actual) {
+				t.Errorf("Expected %s but got %s", format(testCase.expected), format(actual))
+			}
+		})
+	}
+}
+
+func TestSplit_Unhappy(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "unterminated string literal",
+			content: "-- up\nSELECT 'abc;\n-- down\nSELECT 1;\n",
+		},
+		{
+			name:    "unterminated quoted identifier",
+			content: "-- up\nSELECT \"abc;\n",
+		},
+		{
+			name:    "unterminated block comment",
+			content: "-- up\n/* /* */\nSELECT 1;\n",
+		},
+		{
+			name:    "unterminated dollar quoted body",
+			content: "-- up\nDO $body$ BEGIN END $$;\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		content := testCase.content
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := sqlfile.Split(content, sqlfile.CommandUpgrade)
+			if !errors.Is(err, sqlfile.ErrSyntax) {
+				t.Errorf("Expected error %s but got %v", sqlfile.ErrSyntax, err)
+			}
+		})
+	}
+}
+
+func TestSplitWithOptions_BackslashEscapes(t *testing.T) {
+	content := "-- up\nINSERT INTO t VALUES ('It\\'s;', \"say \\\"hi\\\";\", 'C:\\\\');\nSELECT `a\\` FROM t;\n"
+
+	if _, err := sqlfile.Split(content, sqlfile.CommandUpgrade); !errors.Is(err, sqlfile.ErrSyntax) {
+		t.Errorf("Expected error %s without backslash escapes but got %v", sqlfile.ErrSyntax, err)
+	}
+
+	expected := []*sqlfile.Statement{
+		{SQL: "INSERT INTO t VALUES ('It\\'s;', \"say \\\"hi\\\";\", 'C:\\\\')", Line: 2},
+		{SQL: "SELECT `a\\` FROM t", Line: 3},
+	}
+
+	actual, err := sqlfile.SplitWithOptions(content, sqlfile.CommandUpgrade, sqlfile.SplitOptions{BackslashEscapes: true})
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %s but got %s", format(expected), format(actual))
+	}
+}
+
+func TestReadStatements(t *testing.T) {
+	actual, err := sqlfile.ReadStatements("./testdata/Statements/test.sql", sqlfile.CommandUpgrade)
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	expected := []*sqlfile.Statement{
+		{SQL: "CREATE TABLE test (\n    id INTEGER,\n    txt TEXT\n)", Line: 2},
+		{SQL: "INSERT INTO test (id, txt) VALUES (1, 'first;\n  -- not a comment\nsecond')", Line: 8},
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %s but got %s", format(expected), format(actual))
+	}
+
+	if _, err = sqlfile.ReadStatements("./testdata/Statements/not_existing.sql", sqlfile.CommandUpgrade); err == nil {
+		t.Error("Expected an error on not existing file")
+	}
+}
+
+func format(statements []*sqlfile.Statement) string {
+	s := ""
+	for _, v := range statements {
+		s += fmt.Sprintf("\n%d: %q", v.Line, v.SQL)
+	}
+
+	return s
+}
@@ -0,0 +1,15 @@
+-- up
+-- create the tables
+CREATE TABLE IF NOT EXISTS test (
+  id INTEGER
+);
+
+-- another table
+CREATE TABLE IF NOT EXISTS another (
+  id INTEGER
+);
+
+-- down
+-- drop the tables
+DROP TABLE IF EXISTS test;
+DROP TABLE IF EXISTS another;
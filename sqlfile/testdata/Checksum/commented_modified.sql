@@ -0,0 +1,16 @@
+-- up
+-- create the tables
+CREATE TABLE IF NOT EXISTS test (
+  id INTEGER,
+  name TEXT
+);
+
+-- another table
+CREATE TABLE IF NOT EXISTS another (
+  id INTEGER
+);
+
+-- down
+-- drop the tables
+DROP TABLE IF EXISTS test;
+DROP TABLE IF EXISTS another;
@@ -0,0 +1,5 @@
+-- up
+CREATE VIEW IF NOT EXISTS v AS SELECT 1;
+
+-- down
+DROP VIEW IF EXISTS v;
@@ -0,0 +1,9 @@
+-- no-transaction
+-- up
+CREATE INDEX idx_test ON test (id);
+-- no-transaction
+CREATE INDEX idx_another ON another (id);
+
+-- down
+DROP INDEX idx_test;
+DROP INDEX idx_another;
@@ -0,0 +1,6 @@
+-- up
+-- repeatable
+CREATE VIEW IF NOT EXISTS v AS SELECT 1;
+
+-- down
+DROP VIEW IF EXISTS v;
@@ -0,0 +1,13 @@
+-- up
+CREATE TABLE test (
+    id INTEGER,
+    txt TEXT
+);
+
+-- a comment is not a section
+INSERT INTO test (id, txt) VALUES (1, 'first;
+  -- not a comment
+second');
+
+-- down
+DROP TABLE test;
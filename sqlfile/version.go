@@ -0,0 +1,101 @@
+package sqlfile
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidVersion is used if no version can be parsed from a file name
+	ErrInvalidVersion = errors.New("invalid script version")
+)
+
+// Version represents the version of a script parsed from its file name, see ParseVersion().
+type Version []uint64
+
+// ParseVersion returns the version of a script given by the beginning of its file name. Supported are integers
+// ("001_users.sql", "10.sql"), timestamps ("20240101120000_users.sql"), dotted versions ("1.2.3_users.sql") and
+// versions in the style of Flyway ("V1_2__users.sql", "V1.2__users.sql"), where underscores separate the parts until
+// the description starting with a double underscore. The name may also be given without the ending ".sql", like the
+// names of go migrations or a target "1.2" of MigrateTo().
+func ParseVersion(fileName string) (Version, error) {
+	base := path.Base(fileName)
+	name := strings.TrimSuffix(base, ".sql")
+
+	separators := "."
+	if len(name) > 1 && (name[0] == 'V' || name[0] == 'v') && isDigit(name[1]) {
+		name = name[1:]
+		separators = "._"
+	}
+
+	var version Version
+
+	for {
+		end := 0
+		for end < len(name) && isDigit(name[end]) {
+			end++
+		}
+
+		if end == 0 {
+			break
+		}
+
+		part, err := strconv.ParseUint(name[:end], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %s", ErrInvalidVersion, base, err)
+		}
+
+		version = append(version, part)
+		name = name[end:]
+
+		if len(name) < 2 || !strings.ContainsRune(separators, rune(name[0])) || !isDigit(name[1]) {
+			break
+		}
+
+		name = name[1:]
+	}
+
+	if len(version) == 0 {
+		return nil, fmt.Errorf("%w: %s doesn't start with a version", ErrInvalidVersion, base)
+	}
+
+	return version, nil
+}
+
+// Compare returns -1 if v is lower than other, 1 if it is greater and 0 if both are equal. Parts are compared
+// numerically, missing parts count as zero, so "1.0" equals "1".
+func (v Version) Compare(other Version) int {
+	for i := 0; i < len(v) || i < len(other); i++ {
+		var a, b uint64
+
+		if i < len(v) {
+			a = v[i]
+		}
+
+		if i < len(other) {
+			b = other[i]
+		}
+
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// String returns the version with its parts separated by dots, e.g. "1.2".
+func (v Version) String() string {
+	parts := make([]string, len(v))
+	for i, p := range v {
+		parts[i] = strconv.FormatUint(p, 10)
+	}
+
+	return strings.Join(parts, ".")
+}
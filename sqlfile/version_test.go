@@ -0,0 +1,67 @@
+package sqlfile_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rebel-l/schema/sqlfile"
+)
+
+func TestParseVersion(t *testing.T) {
+	testCases := []struct {
+		fileName string
+		expected string
+	}{
+		{fileName: "billing/003_invoices.sql", expected: "3"},
+		{fileName: "10.sql", expected: "10"},
+		{fileName: "20240101120000_users.sql", expected: "20240101120000"},
+		{fileName: "1.2.3_users.sql", expected: "1.2.3"},
+		{fileName: "1.2.x.sql", expected: "1.2"},
+		{fileName: "V1_10__users.sql", expected: "1.10"},
+		{fileName: "v1.2__users.sql", expected: "1.2"},
+		{fileName: "004_2fa_setup.sql", expected: "4"},
+		{fileName: "002_backfill", expected: "2"},
+		{fileName: "1.2", expected: "1.2"},
+		{fileName: "1.2.3", expected: "1.2.3"},
+		{fileName: "1.2.3_backfill", expected: "1.2.3"},
+	}
+
+	for _, testCase := range testCases {
+		actual, err := sqlfile.ParseVersion(testCase.fileName)
+		if err != nil {
+			t.Errorf("Expected no error for %s but got %s", testCase.fileName, err)
+			continue
+		}
+
+		if actual.String() != testCase.expected {
+			t.Errorf("Expected version %s for %s but got %s", testCase.expected, testCase.fileName, actual)
+		}
+	}
+}
+
+func TestParseVersion_Unhappy(t *testing.T) {
+	for _, fileName := range []string{"users.sql", "R__views.sql", "V__users.sql", "99999999999999999999_big.sql"} {
+		if _, err := sqlfile.ParseVersion(fileName); !errors.Is(err, sqlfile.ErrInvalidVersion) {
+			t.Errorf("Expected error %s for %s but got %v", sqlfile.ErrInvalidVersion, fileName, err)
+		}
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	testCases := []struct {
+		a        sqlfile.Version
+		b        sqlfile.Version
+		expected int
+	}{
+		{a: sqlfile.Version{9}, b: sqlfile.Version{10}, expected: -1},
+		{a: sqlfile.Version{1, 10}, b: sqlfile.Version{1, 2}, expected: 1},
+		{a: sqlfile.Version{1}, b: sqlfile.Version{1, 0}, expected: 0},
+		{a: sqlfile.Version{1}, b: sqlfile.Version{1, 1}, expected: -1},
+	}
+
+	for _, testCase := range testCases {
+		if actual := testCase.a.Compare(testCase.b); actual != testCase.expected {
+			t.Errorf("Expected %s compared to %s to be %d but got %d", testCase.a, testCase.b, testCase.expected, actual)
+		}
+	}
+}
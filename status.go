@@ -0,0 +1,181 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rebel-l/schema/store"
+)
+
+const (
+	// StateApplied marks a script which was applied successfully.
+	StateApplied = "applied"
+
+	// StatePending marks a script which was not applied yet.
+	StatePending = "pending"
+
+	// StateFailed marks a script whose execution failed.
+	StateFailed = "failed"
+
+	// StateTimeout marks a script whose execution exceeded its time limit.
+	StateTimeout = "timeout"
+
+	// StateBaseline marks a script which was recorded as applied by Baseline() without executing it.
+	StateBaseline = "baseline"
+
+	// StateMissing marks a script which is logged in the database but missing on disk.
+	StateMissing = "missing"
+)
+
+// ScriptStatus represents the state of a single script.
+type ScriptStatus struct {
+	Script     string     `json:"script"`
+	State      string     `json:"state"`
+	ExecutedAt *time.Time `json:"executed_at,omitempty"`
+	AppVersion string     `json:"app_version,omitempty"`
+	ErrorMsg   string     `json:"error_msg,omitempty"`
+}
+
+// StatusReport represents the state of all scripts returned by Status().
+type StatusReport []*ScriptStatus
+
+// Len returns number of scripts in the report.
+func (r StatusReport) Len() int {
+	return len(r)
+}
+
+// Count returns the number of scripts having the given state.
+func (r StatusReport) Count(state string) int {
+	counter := 0
+
+	for _, v := range r {
+		if v.State == state {
+			counter++
+		}
+	}
+
+	return counter
+}
+
+// Table writes the report as human readable table to w.
+func (r StatusReport) Table(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "SCRIPT\tSTATE\tEXECUTED AT\tAPP VERSION\tERROR")
+
+	for _, v := range r {
+		executedAt := ""
+		if v.ExecutedAt != nil {
+			executedAt = v.ExecutedAt.Format(store.DateTimeFormat)
+		}
+
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", v.Script, v.State, executedAt, v.AppVersion, v.ErrorMsg)
+	}
+
+	return tw.Flush()
+}
+
+// JSON writes the report as JSON to w.
+func (r StatusReport) JSON(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+
+	return e.Encode(r)
+}
+
+// Status returns the state of each script found in path or logged in the database: applied, baseline, pending,
+// failed or missing on disk. It doesn't change anything in the database.
+func (s *Schema) Status(path string) (StatusReport, error) {
+	return s.status(dirSource(path))
+}
+
+// StatusFS does the same as Status but takes the scripts from the given file system, see UpgradeFS().
+func (s *Schema) StatusFS(fsys fs.FS) (StatusReport, error) {
+	return s.status(fsSource(fsys))
+}
+
+func (s *Schema) status(src source) (StatusReport, error) {
+	executedScripts, err := s.executedScripts(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	scripts, err := src.scan(s.migrations, s.scanOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	relativeNames(scripts, executedScripts)
+
+	report := StatusReport{}
+	known := make(map[string]bool)
+
+	for _, sc := range scripts {
+		known[sc.name] = true
+		status := newScriptStatus(sc.name, executedScripts)
+
+		if sc.repeatable && status.State == StateApplied {
+			pending, err := sc.pending(executedScripts)
+			if err != nil {
+				return nil, err
+			}
+
+			if pending {
+				status.State = StatePending
+			}
+		}
+
+		report = append(report, status)
+	}
+
+	for _, e := range executedScripts {
+		if e.Orphaned() || known[e.ScriptName] {
+			continue
+		}
+
+		known[e.ScriptName] = true
+		status := newScriptStatus(e.ScriptName, executedScripts)
+		status.State = StateMissing
+		report = append(report, status)
+	}
+
+	return report, nil
+}
+
+func newScriptStatus(scriptName string, executedScripts store.SchemaScriptCollection) *ScriptStatus {
+	status := &ScriptStatus{Script: scriptName, State: StatePending}
+
+	entry := executedScripts.LastSuccess(scriptName)
+	if entry != nil {
+		status.State = StateApplied
+
+		if entry.Status == store.StatusBaseline {
+			status.State = StateBaseline
+		}
+	} else {
+		for _, v := range executedScripts {
+			if v.Matches(scriptName) && v.Failed() {
+				entry = v
+				status.State = StateFailed
+
+				if v.Status == store.StatusTimeout {
+					status.State = StateTimeout
+				}
+			}
+		}
+	}
+
+	if entry != nil {
+		executedAt := entry.ExecutedAt
+		status.ExecutedAt = &executedAt
+		status.AppVersion = entry.AppVersion
+		status.ErrorMsg = entry.ErrorMsg
+	}
+
+	return status
+}
@@ -0,0 +1,169 @@
+package schema_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rebel-l/schema"
+	"github.com/rebel-l/schema/mocks/schema_mock"
+	"github.com/rebel-l/schema/store"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestSchema_Status(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	executedAt := time.Date(2020, 2, 1, 10, 30, 0, 0, time.UTC)
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+
+	mockScripter := schema_mock.NewMockScripter(ctrl)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(store.SchemaScriptCollection{
+		&store.SchemaScript{
+			ScriptName: "001.sql",
+			Status:     store.StatusSuccess,
+			ExecutedAt: executedAt,
+			AppVersion: "1.0.0",
+		},
+		&store.SchemaScript{
+			ScriptName: "002.sql",
+			Status:     store.StatusError,
+			ExecutedAt: executedAt,
+			ErrorMsg:   "syntax error",
+		},
+		&store.SchemaScript{ScriptName: "003.sql", Status: store.StatusSuccess, ExecutedAt: executedAt},
+	}, nil)
+
+	s := newSchema(t, mockDB, schema.WithScripter(mockScripter))
+
+	report, err := s.Status("./testdata/unit")
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	expected := []struct {
+		script string
+		state  string
+	}{
+		{script: "001.sql", state: schema.StateApplied},
+		{script: "002.sql", state: schema.StateFailed},
+		{script: "003.sql", state: schema.StateMissing},
+	}
+
+	if report.Len() != len(expected) {
+		t.Fatalf("Expected %d scripts but got %d", len(expected), report.Len())
+	}
+
+	for i, e := range expected {
+		if report[i].Script != e.script || report[i].State != e.state {
+			t.Errorf("Expected script %s in state %s but got %#v", e.script, e.state, report[i])
+		}
+	}
+
+	if report[0].AppVersion != "1.0.0" || report[0].ExecutedAt == nil || !report[0].ExecutedAt.Equal(executedAt) {
+		t.Errorf("Expected execution details of applied script but got %#v", report[0])
+	}
+
+	if report[1].ErrorMsg != "syntax error" {
+		t.Errorf("Expected error message of failed script but got '%s'", report[1].ErrorMsg)
+	}
+}
+
+func TestSchema_Status_NewDatabase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(errors.New("no table")) // nolint: goerr113
+
+	s := newSchema(t, mockDB)
+
+	report, err := s.Status("./testdata/unit")
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if report.Count(schema.StatePending) != 2 {
+		t.Errorf("Expected 2 pending scripts but got %d", report.Count(schema.StatePending))
+	}
+
+	if report[0].ExecutedAt != nil {
+		t.Errorf("Expected no execution time for pending script")
+	}
+}
+
+func TestSchema_Status_Unhappy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+
+	mockScripter := schema_mock.NewMockScripter(ctrl)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(nil, errors.New("failed")) // nolint: goerr113
+
+	s := newSchema(t, mockDB, schema.WithScripter(mockScripter))
+
+	if _, err := s.Status("./testdata/unit"); err == nil {
+		t.Error("Expected error is returned on failed database operation")
+	}
+}
+
+func TestStatusReport_Render(t *testing.T) {
+	executedAt := time.Date(2020, 2, 1, 10, 30, 0, 0, time.UTC)
+	report := schema.StatusReport{
+		&schema.ScriptStatus{Script: "001.sql", State: schema.StateApplied, ExecutedAt: &executedAt, AppVersion: "1.0"},
+		&schema.ScriptStatus{Script: "002.sql", State: schema.StatePending},
+	}
+
+	testCases := []struct {
+		name     string
+		render   func(b *bytes.Buffer) error
+		expected string
+	}{
+		{
+			name:   "table",
+			render: func(b *bytes.Buffer) error { return report.Table(b) },
+			expected: "SCRIPT   STATE    EXECUTED AT           APP VERSION  ERROR\n" +
+				"001.sql  applied  2020-02-01T10:30:00Z  1.0          \n" +
+				"002.sql  pending                                     \n",
+		},
+		{
+			name:   "json",
+			render: func(b *bytes.Buffer) error { return report.JSON(b) },
+			expected: `[
+  {
+    "script": "001.sql",
+    "state": "applied",
+    "executed_at": "2020-02-01T10:30:00Z",
+    "app_version": "1.0"
+  },
+  {
+    "script": "002.sql",
+    "state": "pending"
+  }
+]
+`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		render := testCase.render
+		expected := testCase.expected
+		t.Run(testCase.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := render(&buf); err != nil {
+				t.Fatalf("Expected no error but got %s", err)
+			}
+
+			if buf.String() != expected {
+				t.Errorf("Expected output\n'%s'\nbut got\n'%s'", expected, buf.String())
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package store
 //go:generate mockgen -destination=../mocks/store_mock/database_connector_mock.go -package=store_mock github.com/rebel-l/schema/store DatabaseConnector
 
 import (
+	"context"
+	"database/sql"
 	"io"
 
 	"github.com/jmoiron/sqlx"
@@ -11,8 +13,19 @@ import (
 // DatabaseConnector provides methods to interact with a database.
 type DatabaseConnector interface {
 	sqlx.Execer
+	sqlx.ExecerContext
 	Select(dest interface{}, query string, args ...interface{}) error
 	Get(dest interface{}, query string, args ...interface{}) error
 	io.Closer
 	Rebind(string) string
 }
+
+// Transactioner provides a method to start a transaction. It is implemented by *sql.DB and *sqlx.DB.
+type Transactioner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// Conner provides a dedicated connection of the pool. It is implemented by *sql.DB and *sqlx.DB.
+type Conner interface {
+	Conn(ctx context.Context) (*sql.Conn, error)
+}
@@ -0,0 +1,169 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rebel-l/schema/dialect"
+)
+
+var (
+	// ErrNoAdvisoryLock is used if the database or the connection doesn't support advisory locks
+	ErrNoAdvisoryLock = errors.New("advisory locks are not supported")
+)
+
+// SchemaAdvisoryLocker holds the migration lock as advisory lock of the database instead of a row in the lock table,
+// e.g. by pg_advisory_lock in PostgreSQL, GET_LOCK in MySQL or sp_getapplock in SQL Server. The lock belongs to a
+// dedicated connection, so the database releases it if the process holding it crashes. A ttl isn't needed therefore.
+type SchemaAdvisoryLocker struct {
+	db     Conner
+	lock   string
+	unlock string
+	mutex  *sync.Mutex
+	conn   *sql.Conn
+	owner  string
+}
+
+// NewSchemaAdvisoryLocker returns a new SchemaAdvisoryLocker named after the lock table. It fails with
+// ErrNoAdvisoryLock if the dialect has no advisory locks or db can't provide a dedicated connection.
+func NewSchemaAdvisoryLocker(db DatabaseConnector, d dialect.Dialect, tables Tables) (*SchemaAdvisoryLocker, error) {
+	conner, ok := db.(Conner)
+	if !ok {
+		return nil, fmt.Errorf("%w: database connection can't provide a dedicated connection", ErrNoAdvisoryLock)
+	}
+
+	name := tables.Lock
+	if tables.Namespace != "" {
+		name = tables.Namespace + "." + name
+	}
+
+	lock, unlock := d.AdvisoryLock(name)
+	if lock == "" {
+		return nil, fmt.Errorf("%w by %s", ErrNoAdvisoryLock, d.Name())
+	}
+
+	return &SchemaAdvisoryLocker{db: conner, lock: lock, unlock: unlock, mutex: &sync.Mutex{}}, nil
+}
+
+// Acquire takes the advisory lock for the given owner without waiting. If the lock is held by another session,
+// ErrLocked is returned. The ttl is ignored.
+func (sal *SchemaAdvisoryLocker) Acquire(owner string, ttl time.Duration) error {
+	return sal.AcquireContext(context.Background(), owner, ttl)
+}
+
+// AcquireContext does the same as Acquire but cancels the query if the context is done.
+func (sal *SchemaAdvisoryLocker) AcquireContext(ctx context.Context, owner string, _ time.Duration) error {
+	if owner == "" {
+		return fmt.Errorf("SchemaAdvisoryLocker, acquire: %w", ErrNoOwner)
+	}
+
+	sal.mutex.Lock()
+	defer sal.mutex.Unlock()
+
+	if sal.conn != nil {
+		return fmt.Errorf("%w by %s", ErrLocked, sal.owner)
+	}
+
+	conn, err := sal.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("SchemaAdvisoryLocker, acquire failed: %w", err)
+	}
+
+	var acquired int
+	if err = conn.QueryRowContext(ctx, sal.lock).Scan(&acquired); err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("SchemaAdvisoryLocker, acquire failed: %w", err)
+	}
+
+	if acquired != 1 {
+		_ = conn.Close()
+		return fmt.Errorf("%w by another session", ErrLocked)
+	}
+
+	sal.conn = conn
+	sal.owner = owner
+
+	return nil
+}
+
+// Release releases the advisory lock if it is held by the given owner and returns its connection to the pool.
+func (sal *SchemaAdvisoryLocker) Release(owner string) error {
+	return sal.ReleaseContext(context.Background(), owner)
+}
+
+// ReleaseContext does the same as Release but cancels the statement if the context is done.
+func (sal *SchemaAdvisoryLocker) ReleaseContext(ctx context.Context, owner string) error {
+	if owner == "" {
+		return fmt.Errorf("SchemaAdvisoryLocker, release: %w", ErrNoOwner)
+	}
+
+	sal.mutex.Lock()
+	defer sal.mutex.Unlock()
+
+	if sal.conn == nil || sal.owner != owner {
+		return nil
+	}
+
+	return sal.release(ctx)
+}
+
+// ForceRelease releases the advisory lock held by this locker. A lock of another process is released by the database
+// as soon as its connection is closed.
+func (sal *SchemaAdvisoryLocker) ForceRelease() error {
+	sal.mutex.Lock()
+	defer sal.mutex.Unlock()
+
+	if sal.conn == nil {
+		return nil
+	}
+
+	return sal.release(context.Background())
+}
+
+// Extend checks that the connection holding the advisory lock of the given owner is still alive. If it isn't,
+// ErrNotLockOwner is returned as the database released the lock together with the connection.
+func (sal *SchemaAdvisoryLocker) Extend(owner string, ttl time.Duration) error {
+	return sal.ExtendContext(context.Background(), owner, ttl)
+}
+
+// ExtendContext does the same as Extend but cancels the check if the context is done.
+func (sal *SchemaAdvisoryLocker) ExtendContext(ctx context.Context, owner string, _ time.Duration) error {
+	if owner == "" {
+		return fmt.Errorf("SchemaAdvisoryLocker, extend: %w", ErrNoOwner)
+	}
+
+	sal.mutex.Lock()
+	defer sal.mutex.Unlock()
+
+	if sal.conn == nil || sal.owner != owner {
+		return fmt.Errorf("SchemaAdvisoryLocker, extend: %w %s", ErrNotLockOwner, owner)
+	}
+
+	if err := sal.conn.PingContext(ctx); err != nil {
+		return fmt.Errorf("SchemaAdvisoryLocker, extend: %w %s: %v", ErrNotLockOwner, owner, err)
+	}
+
+	return nil
+}
+
+func (sal *SchemaAdvisoryLocker) release(ctx context.Context) error {
+	_, err := sal.conn.ExecContext(ctx, sal.unlock)
+
+	closeErr := sal.conn.Close()
+	sal.conn = nil
+	sal.owner = ""
+
+	if err != nil {
+		return fmt.Errorf("SchemaAdvisoryLocker, release failed: %w", err)
+	}
+
+	if closeErr != nil {
+		return fmt.Errorf("SchemaAdvisoryLocker, release failed: %w", closeErr)
+	}
+
+	return nil
+}
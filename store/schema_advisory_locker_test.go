@@ -0,0 +1,118 @@
+package store_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rebel-l/schema/dialect"
+	"github.com/rebel-l/schema/mocks/store_mock"
+	"github.com/rebel-l/schema/store"
+	"github.com/rebel-l/schema/utils/testdb"
+
+	"github.com/golang/mock/gomock"
+)
+
+// advisoryDialect simulates advisory locks on SQLite, the lock query returns the given result.
+type advisoryDialect struct {
+	dialect.SQLite
+	acquired string
+}
+
+func (d advisoryDialect) AdvisoryLock(_ string) (string, string) {
+	return "SELECT " + d.acquired + ";", "SELECT 1;"
+}
+
+func TestNewSchemaAdvisoryLocker_Unhappy(t *testing.T) {
+	if testing.Short() {
+		t.Skipf("skipped because of long running")
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db, err := testdb.GetDB("./testdata/tmp/advisory_lock_unsupported.db")
+	if err != nil {
+		t.Fatalf("not able to open database connection: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	testCases := []struct {
+		name    string
+		db      store.DatabaseConnector
+		dialect dialect.Dialect
+	}{
+		{name: "dialect without advisory locks", db: db, dialect: dialect.SQLite{}},
+		{name: "no dedicated connection", db: store_mock.NewMockDatabaseConnector(ctrl), dialect: dialect.Postgres{}},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := store.NewSchemaAdvisoryLocker(testCase.db, testCase.dialect, store.DefaultTables())
+			if !errors.Is(err, store.ErrNoAdvisoryLock) {
+				t.Errorf("Expected error %s but got %v", store.ErrNoAdvisoryLock, err)
+			}
+		})
+	}
+}
+
+func TestSchemaAdvisoryLocker_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skipf("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/advisory_lock.db")
+	if err != nil {
+		t.Fatalf("not able to open database connection: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	locked, err := store.NewSchemaAdvisoryLocker(db, advisoryDialect{acquired: "0"}, store.DefaultTables())
+	if err != nil {
+		t.Fatalf("No error expected on creating locker: %s", err)
+	}
+
+	if err = locked.Acquire("me", time.Minute); !errors.Is(err, store.ErrLocked) {
+		t.Fatalf("Expected error %s on acquiring held lock but got: %v", store.ErrLocked, err)
+	}
+
+	locker, err := store.NewSchemaAdvisoryLocker(db, advisoryDialect{acquired: "1"}, store.DefaultTables())
+	if err != nil {
+		t.Fatalf("No error expected on creating locker: %s", err)
+	}
+
+	if err = locker.Extend("me", time.Minute); !errors.Is(err, store.ErrNotLockOwner) {
+		t.Fatalf("Expected error %s on extending lock not held but got: %v", store.ErrNotLockOwner, err)
+	}
+
+	if err = locker.Acquire("me", time.Minute); err != nil {
+		t.Fatalf("No error expected on acquiring free lock: %s", err)
+	}
+
+	if err = locker.Acquire("other", time.Minute); !errors.Is(err, store.ErrLocked) {
+		t.Fatalf("Expected error %s on acquiring held lock but got: %v", store.ErrLocked, err)
+	}
+
+	if err = locker.Extend("me", time.Minute); err != nil {
+		t.Fatalf("No error expected on extending lock: %s", err)
+	}
+
+	if err = locker.Release("other"); err != nil {
+		t.Fatalf("No error expected on releasing lock of other owner: %s", err)
+	}
+
+	if err = locker.Release("me"); err != nil {
+		t.Fatalf("No error expected on releasing lock: %s", err)
+	}
+
+	if err = locker.Acquire("other", time.Minute); err != nil {
+		t.Fatalf("No error expected on acquiring released lock: %s", err)
+	}
+
+	if err = locker.ForceRelease(); err != nil {
+		t.Errorf("No error expected on force release: %s", err)
+	}
+}
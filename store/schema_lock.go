@@ -0,0 +1,18 @@
+package store
+
+import (
+	"time"
+)
+
+// SchemaLock represents the lock stored in the database to prevent concurrent schema changes.
+type SchemaLock struct {
+	ID         int64     `db:"id"`
+	Owner      string    `db:"owner"`
+	AcquiredAt time.Time `db:"acquired_at"`
+	ExpiresAt  time.Time `db:"expires_at"`
+}
+
+// Expired returns true if the lock is older than its expiry date.
+func (s *SchemaLock) Expired(now time.Time) bool {
+	return !s.ExpiresAt.After(now)
+}
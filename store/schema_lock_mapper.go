@@ -0,0 +1,167 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rebel-l/schema/dialect"
+)
+
+const (
+	lockID = 1
+)
+
+var (
+	// ErrLocked is used if the lock is held by another owner
+	ErrLocked = errors.New("schema is locked")
+
+	// ErrNoOwner is used if no owner of the lock was provided
+	ErrNoOwner = errors.New("owner must be provided")
+
+	// ErrNotLockOwner is used if the lock is not held by the owner anymore, e.g. it expired and was taken over
+	ErrNotLockOwner = errors.New("lock is not held by owner")
+)
+
+// SchemaLockMapper is responsible for storing the SchemaLock in database.
+type SchemaLockMapper struct {
+	db      DatabaseConnector
+	dialect dialect.Dialect
+	table   string
+}
+
+// NewSchemaLockMapper returns a new SchemaLockMapper. The dialect is detected from the driver of db.
+func NewSchemaLockMapper(db DatabaseConnector) *SchemaLockMapper {
+	return NewSchemaLockMapperWithDialect(db, dialect.Detect(db))
+}
+
+// NewSchemaLockMapperWithDialect returns a new SchemaLockMapper using the given dialect.
+func NewSchemaLockMapperWithDialect(db DatabaseConnector, d dialect.Dialect) *SchemaLockMapper {
+	return NewSchemaLockMapperWithTables(db, d, DefaultTables())
+}
+
+// NewSchemaLockMapperWithTables returns a new SchemaLockMapper using the given dialect and names of the tables.
+func NewSchemaLockMapperWithTables(db DatabaseConnector, d dialect.Dialect, tables Tables) *SchemaLockMapper {
+	return &SchemaLockMapper{db: db, dialect: d, table: tables.Qualified(d, tables.Lock)}
+}
+
+// Acquire stores the lock for the given owner. If the lock is held by another owner, ErrLocked is returned. A lock
+// which is expired is taken over. The lock expires after the given ttl.
+func (slm SchemaLockMapper) Acquire(owner string, ttl time.Duration) error {
+	return slm.AcquireContext(context.Background(), owner, ttl)
+}
+
+// AcquireContext does the same as Acquire but cancels the statements if the context is done.
+func (slm SchemaLockMapper) AcquireContext(ctx context.Context, owner string, ttl time.Duration) error {
+	if owner == "" {
+		return fmt.Errorf("SchemaLockMapper, acquire: %w", ErrNoOwner)
+	}
+
+	now := time.Now().UTC()
+	q := slm.dialect.Rebind(
+		fmt.Sprintf(`INSERT INTO %s (id, owner, acquired_at, expires_at) VALUES (?, ?, ?, ?)`, slm.table),
+	)
+
+	_, err := slm.db.ExecContext(ctx, q, lockID, owner, now.Format(DateTimeFormat), now.Add(ttl).Format(DateTimeFormat))
+	if err == nil {
+		return nil
+	}
+
+	current, getErr := slm.get(ctx)
+	if getErr != nil || current == nil {
+		return fmt.Errorf("SchemaLockMapper, acquire failed: %w", err)
+	}
+
+	if !current.Expired(now) {
+		return fmt.Errorf("%w by %s since %s", ErrLocked, current.Owner, current.AcquiredAt.Format(DateTimeFormat))
+	}
+
+	// take over stale lock, only if no one else was faster
+	q = slm.dialect.Rebind(fmt.Sprintf(`DELETE FROM %s WHERE id = ? AND owner = ?;`, slm.table))
+	if _, err = slm.db.ExecContext(ctx, q, lockID, current.Owner); err != nil {
+		return fmt.Errorf("SchemaLockMapper, remove stale lock failed: %w", err)
+	}
+
+	return slm.AcquireContext(ctx, owner, ttl)
+}
+
+// Release removes the lock if it is held by the given owner.
+func (slm SchemaLockMapper) Release(owner string) error {
+	return slm.ReleaseContext(context.Background(), owner)
+}
+
+// ReleaseContext does the same as Release but cancels the statement if the context is done.
+func (slm SchemaLockMapper) ReleaseContext(ctx context.Context, owner string) error {
+	if owner == "" {
+		return fmt.Errorf("SchemaLockMapper, release: %w", ErrNoOwner)
+	}
+
+	q := slm.dialect.Rebind(fmt.Sprintf(`DELETE FROM %s WHERE id = ? AND owner = ?;`, slm.table))
+	if _, err := slm.db.ExecContext(ctx, q, lockID, owner); err != nil {
+		return fmt.Errorf("SchemaLockMapper, release failed: %w", err)
+	}
+
+	return nil
+}
+
+// Extend moves the expiry of the lock held by the given owner to ttl from now, so a long running migration keeps its
+// lock. If the lock is not held by the owner anymore, ErrNotLockOwner is returned.
+func (slm SchemaLockMapper) Extend(owner string, ttl time.Duration) error {
+	return slm.ExtendContext(context.Background(), owner, ttl)
+}
+
+// ExtendContext does the same as Extend but cancels the statement if the context is done.
+func (slm SchemaLockMapper) ExtendContext(ctx context.Context, owner string, ttl time.Duration) error {
+	if owner == "" {
+		return fmt.Errorf("SchemaLockMapper, extend: %w", ErrNoOwner)
+	}
+
+	q := slm.dialect.Rebind(fmt.Sprintf(`UPDATE %s SET expires_at = ? WHERE id = ? AND owner = ?;`, slm.table))
+
+	res, err := slm.db.ExecContext(ctx, q, time.Now().UTC().Add(ttl).Format(DateTimeFormat), lockID, owner)
+	if err != nil {
+		return fmt.Errorf("SchemaLockMapper, extend failed: %w", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("SchemaLockMapper, extend failed: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("SchemaLockMapper, extend: %w %s", ErrNotLockOwner, owner)
+	}
+
+	return nil
+}
+
+// ForceRelease removes the lock independent of its owner. Use it to clean up a stale lock of a crashed process.
+func (slm SchemaLockMapper) ForceRelease() error {
+	q := fmt.Sprintf(`DELETE FROM %s;`, slm.table)
+	if _, err := slm.db.Exec(q); err != nil {
+		return fmt.Errorf("SchemaLockMapper, force release failed: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the current lock or nil if no lock is held.
+func (slm SchemaLockMapper) Get() (*SchemaLock, error) {
+	return slm.get(context.Background())
+}
+
+func (slm SchemaLockMapper) get(ctx context.Context) (*SchemaLock, error) {
+	var locks []*SchemaLock
+
+	q := slm.dialect.Rebind(fmt.Sprintf(`SELECT * FROM %s WHERE id = ?`, slm.table))
+	if err := selectContext(ctx, slm.db, &locks, q, lockID); err != nil {
+		return nil, fmt.Errorf("SchemaLockMapper, get failed: %w", err)
+	}
+
+	if len(locks) == 0 {
+		return nil, nil // nolint: nilnil
+	}
+
+	return locks[0], nil
+}
@@ -0,0 +1,111 @@
+package store_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rebel-l/schema/store"
+	"github.com/rebel-l/schema/utils/testdb"
+)
+
+func TestSchemaLockMapper_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skipf("skipped because of long running")
+	}
+
+	t.Parallel()
+
+	db, err := testdb.InitDB("./testdata/tmp/lock_integration_tests.db")
+	if err != nil {
+		t.Fatalf("not able to open database connection: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	lm := store.NewSchemaLockMapper(db)
+
+	if err = lm.Acquire("first", time.Hour); err != nil {
+		t.Fatalf("No error expected on acquiring free lock: %s", err)
+	}
+
+	if err = lm.Acquire("second", time.Hour); !errors.Is(err, store.ErrLocked) {
+		t.Fatalf("Expected error %s on acquiring held lock but got: %v", store.ErrLocked, err)
+	}
+
+	if err = lm.Release("second"); err != nil {
+		t.Fatalf("No error expected on releasing lock of other owner: %s", err)
+	}
+
+	lock, err := lm.Get()
+	if err != nil {
+		t.Fatalf("No error expected on loading lock: %s", err)
+	}
+
+	if lock == nil || lock.Owner != "first" {
+		t.Fatalf("Expected lock is still held by first owner but got %#v", lock)
+	}
+
+	if err = lm.Extend("second", time.Hour); !errors.Is(err, store.ErrNotLockOwner) {
+		t.Fatalf("Expected error %s on extending lock of other owner but got: %v", store.ErrNotLockOwner, err)
+	}
+
+	if err = lm.Extend("first", 2*time.Hour); err != nil {
+		t.Fatalf("No error expected on extending lock: %s", err)
+	}
+
+	if err = lm.Release("first"); err != nil {
+		t.Fatalf("No error expected on releasing lock: %s", err)
+	}
+
+	if err = lm.Acquire("second", time.Hour); err != nil {
+		t.Fatalf("No error expected on acquiring released lock: %s", err)
+	}
+
+	if err = lm.ForceRelease(); err != nil {
+		t.Fatalf("No error expected on force release: %s", err)
+	}
+
+	lock, err = lm.Get()
+	if err != nil {
+		t.Fatalf("No error expected on loading lock: %s", err)
+	}
+
+	if lock != nil {
+		t.Errorf("Expected that lock was removed but got %#v", lock)
+	}
+}
+
+func TestSchemaLockMapper_Integration_StaleLock(t *testing.T) {
+	if testing.Short() {
+		t.Skipf("skipped because of long running")
+	}
+
+	t.Parallel()
+
+	db, err := testdb.InitDB("./testdata/tmp/lock_stale_integration_tests.db")
+	if err != nil {
+		t.Fatalf("not able to open database connection: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	lm := store.NewSchemaLockMapper(db)
+
+	if err = lm.Acquire("crashed", -time.Minute); err != nil {
+		t.Fatalf("No error expected on acquiring free lock: %s", err)
+	}
+
+	if err = lm.Acquire("new", time.Hour); err != nil {
+		t.Fatalf("No error expected on taking over stale lock: %s", err)
+	}
+
+	lock, err := lm.Get()
+	if err != nil {
+		t.Fatalf("No error expected on loading lock: %s", err)
+	}
+
+	if lock == nil || lock.Owner != "new" {
+		t.Errorf("Expected lock is held by new owner but got %#v", lock)
+	}
+}
@@ -0,0 +1,187 @@
+package store_test
+
+import (
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rebel-l/schema/dialect"
+	"github.com/rebel-l/schema/store"
+
+	"github.com/golang/mock/gomock"
+	"github.com/rebel-l/schema/mocks/store_mock"
+)
+
+func TestSchemaLockMapper_Acquire_Happy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
+	mockDB.EXPECT().ExecContext(gomock.Any(), gomock.Any(), 1, "me", gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	mapper := store.NewSchemaLockMapper(mockDB)
+	if err := mapper.Acquire("me", time.Minute); err != nil {
+		t.Errorf("error is not expected but got: %s", err)
+	}
+}
+
+func TestSchemaLockMapper_Acquire_Tables(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	q := "INSERT INTO `billing`.`migrations_lock` (id, owner, acquired_at, expires_at) VALUES (?, ?, ?, ?)"
+
+	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
+	mockDB.EXPECT().ExecContext(gomock.Any(), q, 1, "me", gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	mapper := store.NewSchemaLockMapperWithTables(mockDB, dialect.MySQL{}, store.NewTables("billing", "migrations"))
+	if err := mapper.Acquire("me", time.Minute); err != nil {
+		t.Errorf("error is not expected but got: %s", err)
+	}
+}
+
+func TestSchemaLockMapper_Acquire_Unhappy_Locked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
+	mockDB.EXPECT().
+		ExecContext(gomock.Any(), gomock.Any(), 1, "me", gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("unique constraint failed")) // nolint: goerr113
+	mockDB.EXPECT().
+		Select(gomock.Any(), gomock.Any(), 1).
+		SetArg(0, []*store.SchemaLock{{ID: 1, Owner: "other", ExpiresAt: time.Now().Add(time.Hour)}}).
+		Return(nil)
+
+	mapper := store.NewSchemaLockMapper(mockDB)
+	if err := mapper.Acquire("me", time.Minute); !errors.Is(err, store.ErrLocked) {
+		t.Errorf("expected error %s but got: %v", store.ErrLocked, err)
+	}
+}
+
+func TestSchemaLockMapper_Acquire_Unhappy_InsertError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
+	mockDB.EXPECT().
+		ExecContext(gomock.Any(), gomock.Any(), 1, "me", gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("insert failed")) // nolint: goerr113
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any(), 1).Return(nil)
+
+	mapper := store.NewSchemaLockMapper(mockDB)
+
+	err := mapper.Acquire("me", time.Minute)
+	if err == nil || errors.Is(err, store.ErrLocked) {
+		t.Errorf("expected insert error but got: %v", err)
+	}
+}
+
+func TestSchemaLockMapper_Acquire_Unhappy_EmptyOwner(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
+	mockDB.EXPECT().Exec(gomock.Any()).Times(0)
+
+	mapper := store.NewSchemaLockMapper(mockDB)
+	if err := mapper.Acquire("", time.Minute); !errors.Is(err, store.ErrNoOwner) {
+		t.Errorf("expected error %s but got: %v", store.ErrNoOwner, err)
+	}
+}
+
+func TestSchemaLockMapper_Release(t *testing.T) {
+	testCases := []struct {
+		name  string
+		err   error
+		owner string
+	}{
+		{
+			name:  "happy",
+			owner: "me",
+		},
+		{
+			name:  "delete error",
+			owner: "me",
+			err:   errors.New("delete failed"), // nolint: goerr113
+		},
+	}
+
+	for _, testCase := range testCases {
+		owner := testCase.owner
+		expected := testCase.err
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDB := store_mock.NewMockDatabaseConnector(ctrl)
+			mockDB.EXPECT().ExecContext(gomock.Any(), gomock.Any(), 1, owner).Return(nil, expected)
+
+			mapper := store.NewSchemaLockMapper(mockDB)
+			if err := mapper.Release(owner); !errors.Is(err, expected) {
+				t.Errorf("expected error %v but got: %v", expected, err)
+			}
+		})
+	}
+}
+
+func TestSchemaLockMapper_Extend(t *testing.T) {
+	testCases := []struct {
+		name     string
+		result   driver.Result
+		err      error
+		expected error
+	}{
+		{
+			name:   "happy",
+			result: driver.RowsAffected(1),
+		},
+		{
+			name:     "lock lost",
+			result:   driver.RowsAffected(0),
+			expected: store.ErrNotLockOwner,
+		},
+		{
+			name:     "update error",
+			err:      errors.New("update failed"), // nolint: goerr113
+			expected: errors.New("update failed"), // nolint: goerr113
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			q := `UPDATE "schema_lock" SET expires_at = ? WHERE id = ? AND owner = ?;`
+
+			mockDB := store_mock.NewMockDatabaseConnector(ctrl)
+			mockDB.EXPECT().ExecContext(gomock.Any(), q, gomock.Any(), 1, "me").Return(testCase.result, testCase.err)
+
+			err := store.NewSchemaLockMapper(mockDB).Extend("me", time.Minute)
+			if testCase.expected == nil && err != nil {
+				t.Errorf("error is not expected but got: %s", err)
+			}
+
+			if testCase.expected != nil && (err == nil || !strings.Contains(err.Error(), testCase.expected.Error())) {
+				t.Errorf("expected error %s but got: %v", testCase.expected, err)
+			}
+		})
+	}
+}
+
+func TestSchemaLockMapper_ForceRelease(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
+	mockDB.EXPECT().Exec(gomock.Any()).Return(nil, errors.New("delete failed")) // nolint: goerr113
+
+	mapper := store.NewSchemaLockMapper(mockDB)
+	if err := mapper.ForceRelease(); err == nil {
+		t.Error("error is expected on failing delete")
+	}
+}
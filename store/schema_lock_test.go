@@ -0,0 +1,44 @@
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rebel-l/schema/store"
+)
+
+func TestSchemaLock_Expired(t *testing.T) {
+	now := time.Now()
+
+	testCases := []struct {
+		name      string
+		expiresAt time.Time
+		expected  bool
+	}{
+		{
+			name:      "expired",
+			expiresAt: now.Add(-time.Second),
+			expected:  true,
+		},
+		{
+			name:      "expires now",
+			expiresAt: now,
+			expected:  true,
+		},
+		{
+			name:      "not expired",
+			expiresAt: now.Add(time.Second),
+			expected:  false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		lock := &store.SchemaLock{ExpiresAt: testCase.expiresAt}
+		expected := testCase.expected
+		t.Run(testCase.name, func(t *testing.T) {
+			if actual := lock.Expired(now); actual != expected {
+				t.Errorf("Expected %t but got %t", expected, actual)
+			}
+		})
+	}
+}
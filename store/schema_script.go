@@ -1,6 +1,9 @@
 package store
 
 import (
+	"os"
+	"os/user"
+	"strings"
 	"time"
 )
 
@@ -10,6 +13,16 @@ const (
 
 	// StatusError is the status name for 'error'
 	StatusError = "error"
+
+	// StatusTimeout is the status name for 'timeout', used if the execution exceeded its time limit
+	StatusTimeout = "timeout"
+
+	// StatusBaseline is the status name for 'baseline', used for scripts recorded as applied without executing them
+	StatusBaseline = "baseline"
+
+	// StatusOrphaned is the status name for 'orphaned', used for applied scripts which don't exist anymore. They still
+	// count as applied, so they are not executed again if they appear later.
+	StatusOrphaned = "orphaned"
 )
 
 // SchemaScript represents the version information stored in the database.
@@ -20,6 +33,9 @@ type SchemaScript struct {
 	Status     string    `db:"execution_status"`
 	ErrorMsg   string    `db:"error_msg"`
 	AppVersion string    `db:"app_version"`
+	Checksum   string    `db:"checksum"`
+	DurationMS int64     `db:"duration_ms"`
+	ExecutedBy string    `db:"executed_by"`
 }
 
 // NewSchemaScriptSuccess returns a new SchemaScript struct prepared for successful execution.
@@ -29,6 +45,7 @@ func NewSchemaScriptSuccess(scriptName string, appVersion string) *SchemaScript
 		ExecutedAt: time.Now(),
 		Status:     StatusSuccess,
 		AppVersion: appVersion,
+		ExecutedBy: executedBy(),
 	}
 }
 
@@ -40,16 +57,73 @@ func NewSchemaScriptError(scriptName string, appVersion string, errorMsg string)
 		Status:     StatusError,
 		ErrorMsg:   errorMsg,
 		AppVersion: appVersion,
+		ExecutedBy: executedBy(),
 	}
 }
 
+// NewSchemaScriptTimeout returns a new SchemaScript struct prepared for an execution which exceeded its time limit.
+func NewSchemaScriptTimeout(scriptName string, appVersion string, errorMsg string) *SchemaScript {
+	entry := NewSchemaScriptError(scriptName, appVersion, errorMsg)
+	entry.Status = StatusTimeout
+
+	return entry
+}
+
+// NewSchemaScriptBaseline returns a new SchemaScript struct prepared for a script recorded as applied without
+// executing it.
+func NewSchemaScriptBaseline(scriptName string, appVersion string) *SchemaScript {
+	entry := NewSchemaScriptSuccess(scriptName, appVersion)
+	entry.Status = StatusBaseline
+
+	return entry
+}
+
+// executedBy returns the user and the host executing the scripts, e.g. "deploy@web-1".
+func executedBy() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+
+	return name + "@" + host
+}
+
+// Applied returns true if the script was executed successful, recorded by a baseline or marked as orphaned.
+func (s *SchemaScript) Applied() bool {
+	return s.Status == StatusSuccess || s.Status == StatusBaseline || s.Orphaned()
+}
+
+// Orphaned returns true if the script was applied but marked as not existing anymore.
+func (s *SchemaScript) Orphaned() bool {
+	return s.Status == StatusOrphaned
+}
+
+// Failed returns true if the execution of the script failed or exceeded its time limit.
+func (s *SchemaScript) Failed() bool {
+	return s.Status == StatusError || s.Status == StatusTimeout
+}
+
+// Matches returns true if the entry belongs to the given script name. Scripts are named relative to the folder of
+// the scripts, former versions recorded them including the path of the folder, e.g. "./db/001_users.sql". Such an
+// entry matches the relative name "001_users.sql" too, independent of the path the scripts were executed from.
+func (s *SchemaScript) Matches(scriptName string) bool {
+	return s.ScriptName == scriptName || strings.HasSuffix(s.ScriptName, "/"+scriptName)
+}
+
 // SchemaScriptCollection represent an array of SchemaScript providing useful functions.
 type SchemaScriptCollection []*SchemaScript
 
-// ScriptExecuted returns true if the given scriptName was already executed successful.
+// ScriptExecuted returns true if the given scriptName was already executed successful, recorded by a baseline or
+// marked as orphaned.
+// The names are compared by SchemaScript.Matches().
 func (s SchemaScriptCollection) ScriptExecuted(scriptName string) bool {
 	for _, v := range s {
-		if v.ScriptName == scriptName && v.Status == StatusSuccess {
+		if v.Matches(scriptName) && v.Applied() {
 			return true
 		}
 	}
@@ -57,6 +131,20 @@ func (s SchemaScriptCollection) ScriptExecuted(scriptName string) bool {
 	return false
 }
 
+// LastSuccess returns the latest applied entry (see Applied()) of the given scriptName or nil if it was never
+// executed successful. The latest entry is the one with the highest id, independent of the order of the collection.
+func (s SchemaScriptCollection) LastSuccess(scriptName string) *SchemaScript {
+	var last *SchemaScript
+
+	for _, v := range s {
+		if v.Matches(scriptName) && v.Applied() && (last == nil || v.ID >= last.ID) {
+			last = v
+		}
+	}
+
+	return last
+}
+
 // Len returns number of elements in collection.
 func (s SchemaScriptCollection) Len() int {
 	return len(s)
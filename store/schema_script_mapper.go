@@ -1,8 +1,15 @@
 package store
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
+
+	"github.com/rebel-l/schema/dialect"
+
+	"github.com/jmoiron/sqlx"
 )
 
 const (
@@ -19,43 +26,148 @@ var (
 
 	// ErrNoScript is used if no script name was provided
 	ErrNoScript = errors.New("script name must be provided")
+
+	// ErrNoQueryRow is used if the dialect returns the new id as result set but the executor can't query it
+	ErrNoQueryRow = errors.New("executor is not able to query a row")
 )
 
+// rowQueryer is implemented by *sql.DB, *sql.Tx, *sqlx.DB and *sqlx.Tx.
+type rowQueryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// selecter is implemented by *sqlx.DB and *sqlx.Tx. Connections without it are queried by Select() ignoring the
+// context.
+type selecter interface {
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+// selectContext runs the query by SelectContext() if the database connection provides it, otherwise by Select().
+func selectContext(ctx context.Context, db DatabaseConnector, dest interface{}, q string, args ...interface{}) error {
+	if sel, ok := db.(selecter); ok {
+		return sel.SelectContext(ctx, dest, q, args...)
+	}
+
+	return db.Select(dest, q, args...)
+}
+
+// execContext runs the statement by ExecContext() if the executor provides it, otherwise by Exec().
+func execContext(ctx context.Context, exec sqlx.Execer, q string, args ...interface{}) (sql.Result, error) {
+	if e, ok := exec.(sqlx.ExecerContext); ok {
+		return e.ExecContext(ctx, q, args...)
+	}
+
+	return exec.Exec(q, args...)
+}
+
 // SchemaScriptMapper is responsible for mapping and storing SchemaScript struct in database.
 type SchemaScriptMapper struct {
-	db DatabaseConnector
+	db      DatabaseConnector
+	dialect dialect.Dialect
+	table   string
+	logger  *slog.Logger
 }
 
-// NewSchemaScriptMapper returns a new SchemaScriptMapper.
+// NewSchemaScriptMapper returns a new SchemaScriptMapper. The dialect is detected from the driver of db.
 func NewSchemaScriptMapper(db DatabaseConnector) *SchemaScriptMapper {
-	return &SchemaScriptMapper{db: db}
+	return NewSchemaScriptMapperWithDialect(db, dialect.Detect(db))
+}
+
+// NewSchemaScriptMapperWithDialect returns a new SchemaScriptMapper using the given dialect.
+func NewSchemaScriptMapperWithDialect(db DatabaseConnector, d dialect.Dialect) *SchemaScriptMapper {
+	return NewSchemaScriptMapperWithTables(db, d, DefaultTables())
+}
+
+// NewSchemaScriptMapperWithTables returns a new SchemaScriptMapper using the given dialect and names of the tables.
+func NewSchemaScriptMapperWithTables(db DatabaseConnector, d dialect.Dialect, tables Tables) *SchemaScriptMapper {
+	return &SchemaScriptMapper{
+		db:      db,
+		dialect: d,
+		table:   tables.Qualified(d, tables.Script),
+		logger:  slog.New(slog.DiscardHandler),
+	}
+}
+
+// WithLogger writes structured records about the changes of the log of script executions to the logger at level
+// debug. Without a logger nothing is logged.
+func (ssm *SchemaScriptMapper) WithLogger(logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+
+	ssm.logger = logger
 }
 
 // Add adds a new row to the table.
 func (ssm SchemaScriptMapper) Add(entry *SchemaScript) error {
+	return ssm.AddContext(context.Background(), entry)
+}
+
+// AddContext does the same as Add but cancels the insert if the context is done.
+func (ssm SchemaScriptMapper) AddContext(ctx context.Context, entry *SchemaScript) error {
+	return ssm.add(ctx, ssm.db, entry)
+}
+
+// AddWith adds a new row to the table using the given executor, e.g. a running transaction.
+func (ssm SchemaScriptMapper) AddWith(exec sqlx.Execer, entry *SchemaScript) error {
+	return ssm.AddWithContext(context.Background(), exec, entry)
+}
+
+// AddWithContext does the same as AddWith but cancels the insert if the context is done.
+func (ssm SchemaScriptMapper) AddWithContext(ctx context.Context, exec sqlx.Execer, entry *SchemaScript) error {
+	return ssm.add(ctx, exec, entry)
+}
+
+func (ssm SchemaScriptMapper) add(ctx context.Context, exec sqlx.Execer, entry *SchemaScript) error {
 	if entry == nil {
 		return fmt.Errorf("SchemaScriptMapper, add: %w", ErrNoDataset)
 	}
 
-	q := `
-		INSERT INTO schema_script (
-			script_name,
-  			executed_at,
-  			execution_status,
-  			error_msg,
-			app_version
-		) VALUES (?, ?, ?, ?, ?)
-	`
+	if err := ssm.insert(ctx, exec, entry); err != nil {
+		return err
+	}
+
+	ssm.logger.Debug(
+		"script execution logged",
+		slog.String("script", entry.ScriptName),
+		slog.String("status", entry.Status),
+		slog.String("app_version", entry.AppVersion),
+		slog.Int64("duration_ms", entry.DurationMS),
+	)
+
+	return nil
+}
+
+// insert adds the entry to the table and sets its new id.
+func (ssm SchemaScriptMapper) insert(ctx context.Context, exec sqlx.Execer, entry *SchemaScript) error {
+	q, returnsID := ssm.dialect.Insert(
+		ssm.table,
+		"script_name",
+		"executed_at",
+		"execution_status",
+		"error_msg",
+		"app_version",
+		"checksum",
+		"duration_ms",
+		"executed_by",
+	)
 
-	res, err := ssm.db.Exec(
-		q,
+	args := []interface{}{
 		entry.ScriptName,
 		entry.ExecutedAt.Format(DateTimeFormat),
 		entry.Status,
 		entry.ErrorMsg,
 		entry.AppVersion,
-	)
+		entry.Checksum,
+		entry.DurationMS,
+		entry.ExecutedBy,
+	}
+
+	if returnsID {
+		return addReturningID(ctx, exec, q, args, entry)
+	}
 
+	res, err := execContext(ctx, exec, q, args...)
 	if err != nil {
 		return fmt.Errorf("SchemaScriptMapper, add failed: %w", err)
 	}
@@ -68,17 +180,122 @@ func (ssm SchemaScriptMapper) Add(entry *SchemaScript) error {
 	return nil
 }
 
+// addReturningID executes an insert statement which returns the new id as result set.
+func addReturningID(ctx context.Context, exec sqlx.Execer, q string, args []interface{}, entry *SchemaScript) error {
+	queryer, ok := exec.(rowQueryer)
+	if !ok {
+		return fmt.Errorf("SchemaScriptMapper, add: %w", ErrNoQueryRow)
+	}
+
+	if err := queryer.QueryRowContext(ctx, q, args...).Scan(&entry.ID); err != nil {
+		return fmt.Errorf("SchemaScriptMapper, add failed: %w", err)
+	}
+
+	return nil
+}
+
 // Remove deletes an entry from table based on scriptName.
 func (ssm *SchemaScriptMapper) Remove(scriptName string) error {
+	return ssm.RemoveContext(context.Background(), scriptName)
+}
+
+// RemoveContext does the same as Remove but cancels the deletion if the context is done.
+func (ssm *SchemaScriptMapper) RemoveContext(ctx context.Context, scriptName string) error {
+	return ssm.RemoveWithContext(ctx, ssm.db, scriptName)
+}
+
+// RemoveWith deletes an entry from table based on scriptName using the given executor, e.g. a running transaction.
+func (ssm *SchemaScriptMapper) RemoveWith(exec sqlx.Execer, scriptName string) error {
+	return ssm.RemoveWithContext(context.Background(), exec, scriptName)
+}
+
+// RemoveWithContext does the same as RemoveWith but cancels the deletion if the context is done.
+func (ssm *SchemaScriptMapper) RemoveWithContext(ctx context.Context, exec sqlx.Execer, scriptName string) error {
 	if scriptName == "" {
 		return fmt.Errorf("SchemaScriptMapper, remove: %w", ErrNoScript)
 	}
 
-	q := `DELETE FROM schema_script WHERE script_name = ?;`
-	if _, err := ssm.db.Exec(q, scriptName); err != nil {
+	q := ssm.dialect.Rebind(fmt.Sprintf(`DELETE FROM %s WHERE script_name = ?;`, ssm.table))
+	if _, err := execContext(ctx, exec, q, scriptName); err != nil {
 		return err
 	}
 
+	ssm.logger.Debug("script execution removed", slog.String("script", scriptName))
+
+	return nil
+}
+
+// RemoveByID deletes a single entry from table based on its id.
+func (ssm *SchemaScriptMapper) RemoveByID(id int64) error {
+	return ssm.RemoveByIDContext(context.Background(), id)
+}
+
+// RemoveByIDContext does the same as RemoveByID but cancels the deletion if the context is done.
+func (ssm *SchemaScriptMapper) RemoveByIDContext(ctx context.Context, id int64) error {
+	if id < 1 {
+		return fmt.Errorf("SchemaScriptMapper, remove by id: %w", ErrNoID)
+	}
+
+	q := ssm.dialect.Rebind(fmt.Sprintf(`DELETE FROM %s WHERE id = ?;`, ssm.table))
+	if _, err := ssm.db.ExecContext(ctx, q, id); err != nil {
+		return fmt.Errorf("SchemaScriptMapper, remove by id failed: %w", err)
+	}
+
+	ssm.logger.Debug("script execution removed", slog.Int64("id", id))
+
+	return nil
+}
+
+// Update stores the status, error message and checksum of an existing entry.
+func (ssm *SchemaScriptMapper) Update(entry *SchemaScript) error {
+	return ssm.UpdateContext(context.Background(), entry)
+}
+
+// UpdateContext does the same as Update but cancels the update if the context is done.
+func (ssm *SchemaScriptMapper) UpdateContext(ctx context.Context, entry *SchemaScript) error {
+	if entry == nil {
+		return fmt.Errorf("SchemaScriptMapper, update: %w", ErrNoDataset)
+	}
+
+	if entry.ID < 1 {
+		return fmt.Errorf("SchemaScriptMapper, update: %w", ErrNoID)
+	}
+
+	q := ssm.dialect.Rebind(fmt.Sprintf(
+		`UPDATE %s SET execution_status = ?, error_msg = ?, checksum = ? WHERE id = ?;`,
+		ssm.table,
+	))
+	if _, err := ssm.db.ExecContext(ctx, q, entry.Status, entry.ErrorMsg, entry.Checksum, entry.ID); err != nil {
+		return fmt.Errorf("SchemaScriptMapper, update failed: %w", err)
+	}
+
+	ssm.logger.Debug(
+		"script execution updated",
+		slog.String("script", entry.ScriptName),
+		slog.String("status", entry.Status),
+	)
+
+	return nil
+}
+
+// Rename changes the script name of all entries named oldName to newName.
+func (ssm *SchemaScriptMapper) Rename(oldName string, newName string) error {
+	return ssm.RenameContext(context.Background(), oldName, newName)
+}
+
+// RenameContext does the same as Rename but cancels the update if the context is done.
+func (ssm *SchemaScriptMapper) RenameContext(ctx context.Context, oldName string, newName string) error {
+	if oldName == "" || newName == "" {
+		return fmt.Errorf("SchemaScriptMapper, rename: %w", ErrNoScript)
+	}
+
+	q := ssm.dialect.Rebind(fmt.Sprintf(`UPDATE %s SET script_name = ? WHERE script_name = ?;`, ssm.table))
+	if _, err := ssm.db.ExecContext(ctx, q, newName, oldName); err != nil {
+		return fmt.Errorf("SchemaScriptMapper, rename failed: %w", err)
+	}
+
+	ssm.logger.Debug("script execution renamed", slog.String("from", oldName), slog.String("to", newName))
+
 	return nil
 }
 
@@ -89,7 +306,7 @@ func (ssm SchemaScriptMapper) GetByID(id int64) (*SchemaScript, error) {
 	}
 
 	sv := &SchemaScript{}
-	q := `SELECT * from schema_script WHERE id = ?`
+	q := ssm.dialect.Rebind(fmt.Sprintf(`SELECT * FROM %s WHERE id = ?`, ssm.table))
 
 	if err := ssm.db.Get(sv, q, id); err != nil {
 		return nil, fmt.Errorf("SchemaScriptMapper, get by id failed: %w", err)
@@ -98,12 +315,35 @@ func (ssm SchemaScriptMapper) GetByID(id int64) (*SchemaScript, error) {
 	return sv, nil
 }
 
-// GetAll returns all SchemaScript entries.
+// GetAll returns all SchemaScript entries ordered by their id, so in the order they were recorded.
 func (ssm SchemaScriptMapper) GetAll() (SchemaScriptCollection, error) {
+	return ssm.GetAllContext(context.Background())
+}
+
+// GetAllContext does the same as GetAll but cancels the query if the context is done.
+func (ssm SchemaScriptMapper) GetAllContext(ctx context.Context) (SchemaScriptCollection, error) {
+	var versions []*SchemaScript
+
+	q := fmt.Sprintf(`SELECT * FROM %s ORDER BY id`, ssm.table)
+	if err := selectContext(ctx, ssm.db, &versions, q); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// GetFailed returns all SchemaScript entries of failed executions, including the ones exceeding their time limit,
+// ordered by their id.
+func (ssm SchemaScriptMapper) GetFailed() (SchemaScriptCollection, error) {
+	return ssm.GetFailedContext(context.Background())
+}
+
+// GetFailedContext does the same as GetFailed but cancels the query if the context is done.
+func (ssm SchemaScriptMapper) GetFailedContext(ctx context.Context) (SchemaScriptCollection, error) {
 	var versions []*SchemaScript
 
-	q := `SELECT * FROM schema_script`
-	if err := ssm.db.Select(&versions, q); err != nil {
+	q := ssm.dialect.Rebind(fmt.Sprintf(`SELECT * FROM %s WHERE execution_status IN (?, ?) ORDER BY id`, ssm.table))
+	if err := selectContext(ctx, ssm.db, &versions, q, StatusError, StatusTimeout); err != nil {
 		return nil, err
 	}
 
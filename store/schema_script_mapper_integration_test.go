@@ -1,9 +1,12 @@
 package store_test
 
 import (
+	"context"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/rebel-l/schema/dialect"
 	"github.com/rebel-l/schema/store"
 	"github.com/rebel-l/schema/utils/testdb"
 )
@@ -44,6 +47,84 @@ func TestSchemaScriptMapper_Add_Integration(t *testing.T) {
 	}
 }
 
+// returningSQLite is a SQLite dialect returning the new id by "RETURNING id" like Postgres does.
+type returningSQLite struct {
+	dialect.SQLite
+}
+
+func (d returningSQLite) Insert(table string, columns ...string) (string, bool) {
+	q, _ := d.SQLite.Insert(table, columns...)
+
+	return strings.TrimSuffix(q, ";") + " RETURNING id;", true
+}
+
+func TestSchemaScriptMapper_Add_Integration_ReturningID(t *testing.T) {
+	if testing.Short() {
+		t.Skipf("skipped because of long running")
+	}
+
+	t.Parallel()
+
+	db, err := testdb.InitDB("./testdata/tmp/add_returning_id_integration_tests.db")
+	if err != nil {
+		t.Fatalf("not able to open database connection: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	vm := store.NewSchemaScriptMapperWithDialect(db, returningSQLite{})
+
+	for i := int64(1); i <= 2; i++ {
+		entry := store.NewSchemaScriptSuccess("some_script.sql", "0.5.2")
+		if err = vm.Add(entry); err != nil {
+			t.Fatalf("No error expected on adding entry to database: %s", err)
+		}
+
+		if entry.ID != i {
+			t.Errorf("Expected that id is set with %d but got %d", i, entry.ID)
+		}
+	}
+}
+
+func TestSchemaScriptMapper_AddWith_Integration_Rollback(t *testing.T) {
+	if testing.Short() {
+		t.Skipf("skipped because of long running")
+	}
+
+	t.Parallel()
+
+	db, err := testdb.InitDB("./testdata/tmp/add_with_integration_tests.db")
+	if err != nil {
+		t.Fatalf("not able to open database connection: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	tx, err := db.(store.Transactioner).BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("not able to start transaction: %s", err)
+	}
+
+	// now the test
+	vm := store.NewSchemaScriptMapper(db)
+	if err = vm.AddWith(tx, store.NewSchemaScriptSuccess("some_script.sql", "0.5.2")); err != nil {
+		t.Fatalf("No error expected on adding entry to database: %s", err)
+	}
+
+	if err = tx.Rollback(); err != nil {
+		t.Fatalf("not able to rollback transaction: %s", err)
+	}
+
+	actual, err := vm.GetAll()
+	if err != nil {
+		t.Fatalf("Failed to get all data: %s", err)
+	}
+
+	if actual.Len() != 0 {
+		t.Errorf("Expected that entry was rolled back but got %d rows", actual.Len())
+	}
+}
+
 func TestSchemaScriptMapper_Remove_Integration(t *testing.T) {
 	if testing.Short() {
 		t.Skipf("skipped because of long running")
@@ -86,6 +167,44 @@ func TestSchemaScriptMapper_Remove_Integration(t *testing.T) {
 	}
 }
 
+func TestSchemaScriptMapper_Rename_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skipf("skipped because of long running")
+	}
+
+	t.Parallel()
+
+	db, err := testdb.InitDB("./testdata/tmp/rename_integration_tests.db")
+	if err != nil {
+		t.Fatalf("not able to open database connection: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	sm := store.NewSchemaScriptMapper(db)
+	for _, name := range []string{"./db/my.sql", "./db/my.sql", "./db/other.sql"} {
+		if err = sm.Add(store.NewSchemaScriptSuccess(name, "")); err != nil {
+			t.Fatalf("Failed to prepare data: %s", err)
+		}
+	}
+
+	if err = sm.Rename("./db/my.sql", "my.sql"); err != nil {
+		t.Fatalf("Failed to rename entries: %s", err)
+	}
+
+	data, err := sm.GetAll()
+	if err != nil {
+		t.Fatalf("Failed to get all data: %s", err)
+	}
+
+	expected := []string{"my.sql", "my.sql", "./db/other.sql"}
+	for i, v := range data {
+		if v.ScriptName != expected[i] {
+			t.Errorf("Expected entry %d to be named %s but got %s", i, expected[i], v.ScriptName)
+		}
+	}
+}
+
 func TestSchemaScriptMapper_GetByID_Integration(t *testing.T) { // nolint: gocognit
 	if testing.Short() {
 		t.Skipf("skipped because of long running")
@@ -194,6 +313,7 @@ func TestSchemaScriptMapper_GetAll_Integration(t *testing.T) {
 		store.NewSchemaScriptSuccess("success.sql", "0.7.3"),
 		store.NewSchemaScriptError("error.sql", "", "a message"),
 	}
+	expected[0].Checksum = "a checksum"
 
 	vm := store.NewSchemaScriptMapper(db)
 
@@ -239,5 +359,76 @@ func TestSchemaScriptMapper_GetAll_Integration(t *testing.T) {
 		if e.AppVersion != a.AppVersion {
 			t.Errorf("Expected app version '%s' but got '%s'", e.AppVersion, a.AppVersion)
 		}
+
+		if e.Checksum != a.Checksum {
+			t.Errorf("Expected checksum '%s' but got '%s'", e.Checksum, a.Checksum)
+		}
+	}
+}
+
+func TestSchemaScriptMapper_GetFailed_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skipf("skipped because of long running")
+	}
+
+	t.Parallel()
+
+	db, err := testdb.InitDB("./testdata/tmp/getfailed_integration_tests.db")
+	if err != nil {
+		t.Fatalf("not able to open database connection: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	entries := []*store.SchemaScript{
+		store.NewSchemaScriptSuccess("success.sql", ""),
+		store.NewSchemaScriptError("error.sql", "", "a message"),
+		store.NewSchemaScriptTimeout("timeout.sql", "", "a message"),
+	}
+
+	vm := store.NewSchemaScriptMapper(db)
+
+	for _, v := range entries {
+		if err = vm.Add(v); err != nil {
+			t.Fatalf("No error expected on adding entry to database: %s", err)
+		}
+	}
+
+	actual, err := vm.GetFailed()
+	if err != nil {
+		t.Fatalf("No error expected on loading entries from database: %s", err)
+	}
+
+	if actual.Len() != 2 || actual[0].ScriptName != "error.sql" || actual[1].ScriptName != "timeout.sql" {
+		t.Fatalf("Expected the failed entries but got %#v", actual)
+	}
+
+	if err = vm.RemoveByID(actual[0].ID); err != nil {
+		t.Fatalf("No error expected on removing entry: %s", err)
+	}
+
+	entries[0].Status = store.StatusOrphaned
+	entries[0].Checksum = "new checksum"
+
+	if err = vm.Update(entries[0]); err != nil {
+		t.Fatalf("No error expected on updating entry: %s", err)
+	}
+
+	updated, err := vm.GetByID(entries[0].ID)
+	if err != nil {
+		t.Fatalf("No error expected on loading entry: %s", err)
+	}
+
+	if updated.Status != store.StatusOrphaned || updated.Checksum != "new checksum" {
+		t.Errorf("Expected entry to be updated but got %#v", updated)
+	}
+
+	all, err := vm.GetAll()
+	if err != nil {
+		t.Fatalf("No error expected on loading entries from database: %s", err)
+	}
+
+	if all.Len() != 2 {
+		t.Errorf("Expected 2 entries after removing one but got %d", all.Len())
 	}
 }
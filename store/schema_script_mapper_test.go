@@ -1,9 +1,13 @@
 package store_test
 
 import (
+	"bytes"
 	"errors"
+	"log/slog"
+	"strings"
 	"testing"
 
+	"github.com/rebel-l/schema/dialect"
 	"github.com/rebel-l/schema/store"
 
 	"github.com/golang/mock/gomock"
@@ -23,13 +27,17 @@ func TestSchemaScriptMapper_Add_Happy(t *testing.T) {
 
 	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
 	mockDB.EXPECT().
-		Exec(
+		ExecContext(
+			gomock.Any(),
 			gomock.Any(),
 			script.ScriptName,
 			script.ExecutedAt.Format(store.DateTimeFormat),
 			script.Status,
 			script.ErrorMsg,
 			script.AppVersion,
+			script.Checksum,
+			script.DurationMS,
+			script.ExecutedBy,
 		).Return(mockRes, nil)
 
 	mapper := store.NewSchemaScriptMapper(mockDB)
@@ -42,6 +50,94 @@ func TestSchemaScriptMapper_Add_Happy(t *testing.T) {
 	}
 }
 
+func TestSchemaScriptMapper_WithLogger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRes := mocks.NewMockResult(ctrl)
+	mockRes.EXPECT().LastInsertId().Return(int64(1), nil)
+
+	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
+	mockDB.EXPECT().ExecContext(gomock.Any(), gomock.Any(), gomock.Any()).Return(mockRes, nil)
+	mockDB.EXPECT().ExecContext(gomock.Any(), gomock.Any(), "new.sql", "old.sql").
+		Return(nil, errors.New("failed")) // nolint: goerr113
+
+	buf := &bytes.Buffer{}
+
+	mapper := store.NewSchemaScriptMapper(mockDB)
+	mapper.WithLogger(slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	if err := mapper.Add(store.NewSchemaScriptSuccess("my_sql_script.sql", "0.1.0")); err != nil {
+		t.Fatalf("error is not expected but got: %s", err)
+	}
+
+	if err := mapper.Rename("old.sql", "new.sql"); err == nil {
+		t.Fatal("error is expected on failing update")
+	}
+
+	expected := []string{
+		"level=DEBUG", `msg="script execution logged"`, "script=my_sql_script.sql", "status=success",
+		"app_version=0.1.0",
+	}
+
+	for _, e := range expected {
+		if !strings.Contains(buf.String(), e) {
+			t.Errorf("Expected %s in log but got %q", e, buf.String())
+		}
+	}
+
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("Expected only one record but got %q", buf.String())
+	}
+}
+
+func TestSchemaScriptMapper_Add_Unhappy_NoQueryRow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
+	mockDB.EXPECT().Exec(gomock.Any()).Times(0)
+
+	mapper := store.NewSchemaScriptMapperWithDialect(mockDB, dialect.Postgres{})
+
+	err := mapper.Add(store.NewSchemaScriptSuccess("my_sql_script.sql", "0.1.0"))
+	if !errors.Is(err, store.ErrNoQueryRow) {
+		t.Errorf("Expected error %s but got %v", store.ErrNoQueryRow, err)
+	}
+}
+
+func TestSchemaScriptMapper_Remove_Dialect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
+	mockDB.EXPECT().
+		ExecContext(gomock.Any(), `DELETE FROM "schema_script" WHERE script_name = $1;`, "my_sql_script.sql").
+		Return(nil, nil)
+
+	mapper := store.NewSchemaScriptMapperWithDialect(mockDB, dialect.Postgres{})
+	if err := mapper.Remove("my_sql_script.sql"); err != nil {
+		t.Errorf("error is not expected but got: %s", err)
+	}
+}
+
+func TestSchemaScriptMapper_Remove_Tables(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
+	mockDB.EXPECT().
+		ExecContext(gomock.Any(), "DELETE FROM [billing].[migrations] WHERE script_name = @p1;", "my_sql_script.sql").
+		Return(nil, nil)
+
+	tables := store.NewTables("billing", "migrations")
+
+	mapper := store.NewSchemaScriptMapperWithTables(mockDB, dialect.SQLServer{}, tables)
+	if err := mapper.Remove("my_sql_script.sql"); err != nil {
+		t.Errorf("error is not expected but got: %s", err)
+	}
+}
+
 func TestSchemaScriptMapper_Add_Unhappy_NilEntry(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -66,13 +162,17 @@ func TestSchemaScriptMapper_Add_Unhappy_InsertError(t *testing.T) {
 
 	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
 	mockDB.EXPECT().
-		Exec(
+		ExecContext(
+			gomock.Any(),
 			gomock.Any(),
 			script.ScriptName,
 			script.ExecutedAt.Format(store.DateTimeFormat),
 			script.Status,
 			script.ErrorMsg,
 			script.AppVersion,
+			script.Checksum,
+			script.DurationMS,
+			script.ExecutedBy,
 		).Return(mockRes, errors.New("insert failed")) // nolint: goerr113
 
 	mapper := store.NewSchemaScriptMapper(mockDB)
@@ -92,13 +192,17 @@ func TestSchemaScriptMapper_Add_Unhappy_LastInsertError(t *testing.T) {
 
 	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
 	mockDB.EXPECT().
-		Exec(
+		ExecContext(
+			gomock.Any(),
 			gomock.Any(),
 			script.ScriptName,
 			script.ExecutedAt.Format(store.DateTimeFormat),
 			script.Status,
 			script.ErrorMsg,
 			script.AppVersion,
+			script.Checksum,
+			script.DurationMS,
+			script.ExecutedBy,
 		).Return(mockRes, nil)
 
 	mapper := store.NewSchemaScriptMapper(mockDB)
@@ -118,7 +222,7 @@ func TestSchemaScriptMapper_Remove_Happy(t *testing.T) {
 
 	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
 	mockDB.EXPECT().
-		Exec(gomock.Any(), scriptName).
+		ExecContext(gomock.Any(), gomock.Any(), scriptName).
 		Return(mockRes, nil)
 
 	mapper := store.NewSchemaScriptMapper(mockDB)
@@ -138,7 +242,7 @@ func TestSchemaScriptMapper_Remove_Unhappy_DeleteError(t *testing.T) {
 
 	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
 	mockDB.EXPECT().
-		Exec(gomock.Any(), scriptName).
+		ExecContext(gomock.Any(), gomock.Any(), scriptName).
 		Return(mockRes, errors.New("delete failed")) // nolint: goerr113
 
 	mapper := store.NewSchemaScriptMapper(mockDB)
@@ -241,7 +345,7 @@ func TestSchemaScriptMapper_GetAll_Happy(t *testing.T) {
 
 	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
 	empty := make([]interface{}, 0)
-	mockDB.EXPECT().Select(gomock.Any(), gomock.Any(), gomock.Eq(empty))
+	mockDB.EXPECT().Select(gomock.Any(), `SELECT * FROM "schema_script" ORDER BY id`, gomock.Eq(empty))
 
 	mapper := store.NewSchemaScriptMapper(mockDB)
 
@@ -272,3 +376,168 @@ func TestSchemaScriptMapper_GetAll_Unhappy(t *testing.T) {
 		t.Errorf("returned list of schema versions should be nil on error")
 	}
 }
+
+func TestSchemaScriptMapper_RemoveByID(t *testing.T) {
+	testCases := []struct {
+		name    string
+		id      int64
+		calls   int
+		execErr error
+		wantErr bool
+	}{
+		{
+			name:  "happy",
+			id:    7,
+			calls: 1,
+		},
+		{
+			name:    "no id",
+			wantErr: true,
+		},
+		{
+			name:    "delete error",
+			id:      7,
+			calls:   1,
+			execErr: errors.New("delete failed"), // nolint: goerr113
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDB := store_mock.NewMockDatabaseConnector(ctrl)
+			mockDB.EXPECT().
+				ExecContext(gomock.Any(), gomock.Any(), testCase.id).
+				Times(testCase.calls).
+				Return(nil, testCase.execErr)
+
+			mapper := store.NewSchemaScriptMapper(mockDB)
+			if err := mapper.RemoveByID(testCase.id); (err != nil) != testCase.wantErr {
+				t.Errorf("Expected error to be %t but got %v", testCase.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSchemaScriptMapper_Rename(t *testing.T) {
+	testCases := []struct {
+		name    string
+		oldName string
+		newName string
+		calls   int
+		execErr error
+		wantErr bool
+	}{
+		{
+			name:    "happy",
+			oldName: "./db/001.sql",
+			newName: "001.sql",
+			calls:   1,
+		},
+		{
+			name:    "no old name",
+			newName: "001.sql",
+			wantErr: true,
+		},
+		{
+			name:    "no new name",
+			oldName: "./db/001.sql",
+			wantErr: true,
+		},
+		{
+			name:    "update error",
+			oldName: "./db/001.sql",
+			newName: "001.sql",
+			calls:   1,
+			execErr: errors.New("update failed"), // nolint: goerr113
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDB := store_mock.NewMockDatabaseConnector(ctrl)
+			mockDB.EXPECT().
+				ExecContext(gomock.Any(), gomock.Any(), testCase.newName, testCase.oldName).
+				Times(testCase.calls).
+				Return(nil, testCase.execErr)
+
+			mapper := store.NewSchemaScriptMapper(mockDB)
+			if err := mapper.Rename(testCase.oldName, testCase.newName); (err != nil) != testCase.wantErr {
+				t.Errorf("Expected error to be %t but got %v", testCase.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSchemaScriptMapper_Update(t *testing.T) {
+	testCases := []struct {
+		name    string
+		entry   *store.SchemaScript
+		calls   int
+		wantErr error
+	}{
+		{
+			name:  "happy",
+			entry: &store.SchemaScript{ID: 3, Status: store.StatusOrphaned, Checksum: "abc"},
+			calls: 1,
+		},
+		{
+			name:    "no entry",
+			wantErr: store.ErrNoDataset,
+		},
+		{
+			name:    "no id",
+			entry:   &store.SchemaScript{Status: store.StatusOrphaned},
+			wantErr: store.ErrNoID,
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDB := store_mock.NewMockDatabaseConnector(ctrl)
+			mockDB.EXPECT().
+				ExecContext(gomock.Any(), gomock.Any(), store.StatusOrphaned, "", "abc", int64(3)).
+				Times(testCase.calls).
+				Return(nil, nil)
+
+			mapper := store.NewSchemaScriptMapper(mockDB)
+			if err := mapper.Update(testCase.entry); !errors.Is(err, testCase.wantErr) {
+				t.Errorf("Expected error %v but got %v", testCase.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSchemaScriptMapper_GetFailed_Unhappy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := store_mock.NewMockDatabaseConnector(ctrl)
+	mockDB.EXPECT().
+		Select(gomock.Any(), gomock.Any(), store.StatusError, store.StatusTimeout).
+		Return(errors.New("select failed")) // nolint: goerr113
+
+	mapper := store.NewSchemaScriptMapper(mockDB)
+
+	res, err := mapper.GetFailed()
+	if err == nil {
+		t.Errorf("expected error on reading failure")
+	}
+
+	if res != nil {
+		t.Errorf("returned collection should be nil on error")
+	}
+}
@@ -1,6 +1,7 @@
 package store_test
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -48,6 +49,10 @@ func TestNewSchemaScriptSuccess(t *testing.T) {
 	if actual.ErrorMsg != "" {
 		t.Errorf("expected error mesage to be empty but got '%s'", actual.ErrorMsg)
 	}
+
+	if !strings.Contains(actual.ExecutedBy, "@") {
+		t.Errorf("expected that executedBy is automatically set to user and host but got '%s'", actual.ExecutedBy)
+	}
 }
 
 func TestNewSchemaScriptError(t *testing.T) {
@@ -93,6 +98,76 @@ func TestNewSchemaScriptError(t *testing.T) {
 	}
 }
 
+func TestNewSchemaScriptBaseline(t *testing.T) {
+	actual := store.NewSchemaScriptBaseline("baseline.sql", "0.1.3")
+
+	if actual.ScriptName != "baseline.sql" || actual.AppVersion != "0.1.3" {
+		t.Errorf("expected script name and app version to be set but got %#v", actual)
+	}
+
+	if actual.Status != store.StatusBaseline {
+		t.Errorf("expected status '%s' but got '%s'", store.StatusBaseline, actual.Status)
+	}
+
+	if !actual.Applied() {
+		t.Error("expected baseline to be treated as applied")
+	}
+}
+
+func TestSchemaScript_Matches(t *testing.T) {
+	testCases := []struct {
+		name       string
+		storedName string
+		scriptName string
+		expected   bool
+	}{
+		{
+			name:       "same name",
+			storedName: "001_users.sql",
+			scriptName: "001_users.sql",
+			expected:   true,
+		},
+		{
+			name:       "relative path",
+			storedName: "./db/migrations/001_users.sql",
+			scriptName: "001_users.sql",
+			expected:   true,
+		},
+		{
+			name:       "absolute path",
+			storedName: "/app/db/migrations/users/001_users.sql",
+			scriptName: "users/001_users.sql",
+			expected:   true,
+		},
+		{
+			name:       "other script",
+			storedName: "./db/migrations/1_users.sql",
+			scriptName: "001_users.sql",
+		},
+		{
+			name:       "suffix without folder",
+			storedName: "./db/migrations/x001_users.sql",
+			scriptName: "001_users.sql",
+		},
+		{
+			name:       "stored name is shorter",
+			storedName: "001_users.sql",
+			scriptName: "users/001_users.sql",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			entry := &store.SchemaScript{ScriptName: testCase.storedName}
+			if actual := entry.Matches(testCase.scriptName); actual != testCase.expected {
+				t.Errorf("Expected %t for %s and %s but got %t", testCase.expected, testCase.storedName,
+					testCase.scriptName, actual)
+			}
+		})
+	}
+}
+
 func TestSchemaScriptCollection_ScriptExecuted(t *testing.T) {
 	testCases := []struct {
 		name       string
@@ -155,6 +230,22 @@ func TestSchemaScriptCollection_ScriptExecuted(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name:       "one baseline item in collection",
+			scriptName: "hit.sql",
+			collection: store.SchemaScriptCollection{
+				&store.SchemaScript{ScriptName: "hit.sql", Status: store.StatusBaseline},
+			},
+			expected: true,
+		},
+		{
+			name:       "one orphaned item in collection",
+			scriptName: "hit.sql",
+			collection: store.SchemaScriptCollection{
+				&store.SchemaScript{ScriptName: "hit.sql", Status: store.StatusOrphaned},
+			},
+			expected: true,
+		},
 		{
 			name:       "one error item, one success item in collection",
 			scriptName: "hit.sql",
@@ -164,6 +255,22 @@ func TestSchemaScriptCollection_ScriptExecuted(t *testing.T) {
 			},
 			expected: false,
 		},
+		{
+			name:       "item recorded with full path in collection",
+			scriptName: "hit.sql",
+			collection: store.SchemaScriptCollection{
+				&store.SchemaScript{ScriptName: "./db/migrations/hit.sql", Status: store.StatusSuccess},
+			},
+			expected: true,
+		},
+		{
+			name:       "item recorded with full path in collection, no hit",
+			scriptName: "hit.sql",
+			collection: store.SchemaScriptCollection{
+				&store.SchemaScript{ScriptName: "./db/migrations/nohit.sql", Status: store.StatusSuccess},
+			},
+			expected: false,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -184,3 +291,54 @@ func TestSchemaScriptCollection_ScriptExecuted(t *testing.T) {
 		})
 	}
 }
+
+func TestSchemaScriptCollection_LastSuccess(t *testing.T) {
+	first := &store.SchemaScript{ScriptName: "hit.sql", Status: store.StatusSuccess, Checksum: "first"}
+	last := &store.SchemaScript{ScriptName: "hit.sql", Status: store.StatusSuccess, Checksum: "last"}
+	latest := &store.SchemaScript{ID: 7, ScriptName: "hit.sql", Status: store.StatusSuccess, Checksum: "latest"}
+
+	testCases := []struct {
+		name       string
+		collection store.SchemaScriptCollection
+		expected   *store.SchemaScript
+	}{
+		{
+			name: "empty collection",
+		},
+		{
+			name: "only errors",
+			collection: store.SchemaScriptCollection{
+				&store.SchemaScript{ScriptName: "hit.sql", Status: store.StatusError},
+			},
+		},
+		{
+			name: "latest success wins",
+			collection: store.SchemaScriptCollection{
+				first,
+				&store.SchemaScript{ScriptName: "else.sql", Status: store.StatusSuccess},
+				last,
+				&store.SchemaScript{ScriptName: "hit.sql", Status: store.StatusError},
+			},
+			expected: last,
+		},
+		{
+			name: "highest id wins on unordered collection",
+			collection: store.SchemaScriptCollection{
+				&store.SchemaScript{ID: 3, ScriptName: "hit.sql", Status: store.StatusSuccess, Checksum: "third"},
+				latest,
+				&store.SchemaScript{ID: 1, ScriptName: "hit.sql", Status: store.StatusSuccess, Checksum: "first"},
+			},
+			expected: latest,
+		},
+	}
+
+	for _, testCase := range testCases {
+		collection := testCase.collection
+		expected := testCase.expected
+		t.Run(testCase.name, func(t *testing.T) {
+			if actual := collection.LastSuccess("hit.sql"); actual != expected {
+				t.Errorf("Expected %#v but got %#v", expected, actual)
+			}
+		})
+	}
+}
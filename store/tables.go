@@ -0,0 +1,54 @@
+package store
+
+import "github.com/rebel-l/schema/dialect"
+
+const (
+	// DefaultScriptTable is the default name of the table logging the script executions.
+	DefaultScriptTable = "schema_script"
+
+	// DefaultLockTable is the default name of the table holding the migration lock.
+	DefaultLockTable = "schema_lock"
+
+	// DefaultMetaTable is the default name of the table holding the meta-version of the tables.
+	DefaultMetaTable = "schema_meta"
+)
+
+// Tables contains the unquoted names of the tables of this package and the namespace they belong to. The namespace
+// is the schema in PostgreSQL and SQL Server, the database in MySQL and the attached database in SQLite. An empty
+// namespace stands for the default one of the connection.
+type Tables struct {
+	Namespace string
+	Script    string
+	Lock      string
+	Meta      string
+}
+
+// DefaultTables returns the default names of the tables without namespace.
+func DefaultTables() Tables {
+	return Tables{
+		Script: DefaultScriptTable,
+		Lock:   DefaultLockTable,
+		Meta:   DefaultMetaTable,
+	}
+}
+
+// NewTables returns the tables named after the table logging the script executions, e.g. "billing_script" results
+// in the tables "billing_script", "billing_script_lock" and "billing_script_meta". The default name keeps the
+// default names of all tables.
+func NewTables(namespace string, scriptTable string) Tables {
+	t := DefaultTables()
+	t.Namespace = namespace
+
+	if scriptTable != "" && scriptTable != DefaultScriptTable {
+		t.Script = scriptTable
+		t.Lock = scriptTable + "_lock"
+		t.Meta = scriptTable + "_meta"
+	}
+
+	return t
+}
+
+// Qualified returns the quoted name of the table prefixed by the quoted namespace, ready to be used in statements.
+func (t Tables) Qualified(d dialect.Dialect, table string) string {
+	return dialect.QualifiedName(d, t.Namespace, table)
+}
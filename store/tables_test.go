@@ -0,0 +1,64 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/rebel-l/schema/dialect"
+	"github.com/rebel-l/schema/store"
+)
+
+func TestNewTables(t *testing.T) {
+	testCases := []struct {
+		name        string
+		namespace   string
+		scriptTable string
+		expected    store.Tables
+	}{
+		{
+			name:     "defaults",
+			expected: store.DefaultTables(),
+		},
+		{
+			name:        "default name in namespace",
+			namespace:   "billing",
+			scriptTable: store.DefaultScriptTable,
+			expected: store.Tables{
+				Namespace: "billing",
+				Script:    "schema_script",
+				Lock:      "schema_lock",
+				Meta:      "schema_meta",
+			},
+		},
+		{
+			name:        "custom name",
+			scriptTable: "migrations",
+			expected: store.Tables{
+				Script: "migrations",
+				Lock:   "migrations_lock",
+				Meta:   "migrations_meta",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			if actual := store.NewTables(testCase.namespace, testCase.scriptTable); actual != testCase.expected {
+				t.Errorf("Expected tables %v but got %v", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestTables_Qualified(t *testing.T) {
+	tables := store.NewTables("billing", "migrations")
+
+	if actual := tables.Qualified(dialect.Postgres{}, tables.Meta); actual != `"billing"."migrations_meta"` {
+		t.Errorf(`Expected "billing"."migrations_meta" but got %s`, actual)
+	}
+
+	tables = store.DefaultTables()
+	if actual := tables.Qualified(dialect.Postgres{}, tables.Script); actual != `"schema_script"` {
+		t.Errorf(`Expected "schema_script" but got %s`, actual)
+	}
+}
@@ -0,0 +1,5 @@
+-- up
+CREATE TABLE IF NOT EXISTS first(id INTEGER);
+
+-- down
+DROP TABLE IF EXISTS first;
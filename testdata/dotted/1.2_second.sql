@@ -0,0 +1,5 @@
+-- up
+CREATE TABLE IF NOT EXISTS second(id INTEGER);
+
+-- down
+DROP TABLE IF EXISTS second;
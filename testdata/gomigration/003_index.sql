@@ -0,0 +1,5 @@
+-- up
+CREATE UNIQUE INDEX items_name ON items (name);
+
+-- down
+DROP INDEX IF EXISTS items_name;
@@ -0,0 +1,5 @@
+-- up
+CREATE TABLE IF NOT EXISTS something_else(id INTEGER);
+
+-- down
+DROP TABLE IF EXISTS something_else;
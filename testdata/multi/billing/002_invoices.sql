@@ -0,0 +1,5 @@
+-- up
+CREATE TABLE invoices (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users (id));
+
+-- down
+DROP TABLE IF EXISTS invoices;
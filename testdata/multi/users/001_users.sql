@@ -0,0 +1,5 @@
+-- up
+CREATE TABLE users (id INTEGER PRIMARY KEY, name VARCHAR(50));
+
+-- down
+DROP TABLE IF EXISTS users;
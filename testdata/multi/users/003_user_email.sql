@@ -0,0 +1,7 @@
+-- up
+ALTER TABLE users ADD COLUMN email VARCHAR(100);
+
+-- down
+CREATE TABLE users_backup AS SELECT id, name FROM users;
+DROP TABLE users;
+ALTER TABLE users_backup RENAME TO users;
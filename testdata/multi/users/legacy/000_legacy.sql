@@ -0,0 +1,5 @@
+-- up
+CREATE TABLE legacy (id INTEGER);
+
+-- down
+DROP TABLE IF EXISTS legacy;
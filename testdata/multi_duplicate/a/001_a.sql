@@ -0,0 +1,5 @@
+-- up
+CREATE TABLE a (id INTEGER);
+
+-- down
+DROP TABLE IF EXISTS a;
@@ -0,0 +1,5 @@
+-- up
+CREATE TABLE b (id INTEGER);
+
+-- down
+DROP TABLE IF EXISTS b;
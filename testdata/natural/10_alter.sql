@@ -0,0 +1,7 @@
+-- up
+ALTER TABLE natural ADD COLUMN name VARCHAR(50);
+
+-- down
+CREATE TABLE natural_backup AS SELECT id FROM natural;
+DROP TABLE natural;
+ALTER TABLE natural_backup RENAME TO natural;
@@ -0,0 +1,5 @@
+-- up
+CREATE TABLE natural (id INTEGER);
+
+-- down
+DROP TABLE IF EXISTS natural;
@@ -0,0 +1,5 @@
+-- up
+CREATE TABLE items (id INTEGER PRIMARY KEY, name VARCHAR(50), price INTEGER);
+
+-- down
+DROP TABLE IF EXISTS items;
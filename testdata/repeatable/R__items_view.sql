@@ -0,0 +1,6 @@
+-- up
+DROP VIEW IF EXISTS items_view;
+CREATE VIEW items_view AS SELECT name FROM items;
+
+-- down
+DROP VIEW IF EXISTS items_view;
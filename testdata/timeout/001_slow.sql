@@ -0,0 +1,6 @@
+-- up
+CREATE TABLE slow (id INTEGER);
+WITH RECURSIVE counter(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM counter) INSERT INTO slow SELECT i FROM counter;
+
+-- down
+DROP TABLE IF EXISTS slow;
@@ -0,0 +1,164 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/rebel-l/schema/store"
+)
+
+const (
+	// ProblemModified marks an applied script whose content changed after it was applied.
+	ProblemModified = "modified"
+
+	// ProblemMissing marks an applied script which doesn't exist on disk anymore.
+	ProblemMissing = "missing"
+
+	// ProblemPending marks a script which was never applied.
+	ProblemPending = "pending"
+)
+
+var (
+	// ErrChecksumMismatch is used if applied scripts were modified afterwards
+	ErrChecksumMismatch = errors.New("checksum mismatch of applied scripts")
+)
+
+// Problem describes a difference between the scripts on disk and the scripts applied to the database.
+type Problem struct {
+	Script string
+	Kind   string
+}
+
+// Validation represents the problems found by Validate().
+type Validation []*Problem
+
+// Len returns number of problems.
+func (v Validation) Len() int {
+	return len(v)
+}
+
+// Scripts returns the names of the scripts having a problem of the given kind.
+func (v Validation) Scripts(kind string) []string {
+	scripts := make([]string, 0)
+
+	for _, p := range v {
+		if p.Kind == kind {
+			scripts = append(scripts, p.Script)
+		}
+	}
+
+	return scripts
+}
+
+// Err returns ErrChecksumMismatch if any applied script was modified, otherwise nil.
+func (v Validation) Err() error {
+	modified := v.Scripts(ProblemModified)
+	if len(modified) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrChecksumMismatch, strings.Join(modified, ", "))
+}
+
+// WithoutValidation deactivates the check of Upgrade() that applied scripts weren't modified afterwards.
+func WithoutValidation() Option {
+	return func(s *Schema) error {
+		s.skipValidation = true
+
+		return nil
+	}
+}
+
+// Validate compares the scripts in path with the scripts applied to the database. It reports every applied script
+// whose content changed, which is missing on disk or which was never applied. Scripts which were never applied but
+// are older than the newest applied script are additionally reported as out of order.
+// Scripts applied before checksums were introduced can't be checked for modifications.
+func (s *Schema) Validate(path string) (Validation, error) {
+	return s.validate(dirSource(path))
+}
+
+// ValidateFS does the same as Validate but takes the scripts from the given file system, see UpgradeFS().
+func (s *Schema) ValidateFS(fsys fs.FS) (Validation, error) {
+	return s.validate(fsSource(fsys))
+}
+
+func (s *Schema) validate(src source) (Validation, error) {
+	executedScripts, err := s.executedScripts(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	scripts, err := src.scan(s.migrations, s.scanOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	relativeNames(scripts, executedScripts)
+
+	return validate(scripts, executedScripts)
+}
+
+// checkModifications returns ErrChecksumMismatch if an applied script was modified, unless validation is deactivated.
+func (s *Schema) checkModifications(scripts []*script, executedScripts store.SchemaScriptCollection) error {
+	if s.skipValidation {
+		return nil
+	}
+
+	validation, err := validate(scripts, executedScripts)
+	if err != nil {
+		return err
+	}
+
+	return validation.Err()
+}
+
+func validate(scripts []*script, executedScripts store.SchemaScriptCollection) (Validation, error) {
+	validation := Validation{}
+	known := make(map[string]bool)
+
+	for _, sc := range scripts {
+		known[sc.name] = true
+
+		applied := executedScripts.LastSuccess(sc.name)
+		if applied == nil {
+			validation = append(validation, &Problem{Script: sc.name, Kind: ProblemPending})
+			continue
+		}
+
+		if applied.Checksum == "" {
+			continue
+		}
+
+		checksum, err := sc.checksum()
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case checksum == applied.Checksum:
+			continue
+		case sc.repeatable:
+			validation = append(validation, &Problem{Script: sc.name, Kind: ProblemPending})
+		default:
+			validation = append(validation, &Problem{Script: sc.name, Kind: ProblemModified})
+		}
+	}
+
+	for _, name := range outOfOrder(scripts, executedScripts) {
+		validation = append(validation, &Problem{Script: name, Kind: ProblemOutOfOrder})
+	}
+
+	for _, e := range executedScripts {
+		if !e.Applied() || e.Orphaned() || known[e.ScriptName] {
+			continue
+		}
+
+		known[e.ScriptName] = true
+		validation = append(validation, &Problem{Script: e.ScriptName, Kind: ProblemMissing})
+	}
+
+	return validation, nil
+}
@@ -0,0 +1,236 @@
+package schema_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/rebel-l/go-utils/array"
+	"github.com/rebel-l/go-utils/osutils"
+
+	"github.com/rebel-l/schema"
+	"github.com/rebel-l/schema/mocks/schema_mock"
+	"github.com/rebel-l/schema/sqlfile"
+	"github.com/rebel-l/schema/store"
+	"github.com/rebel-l/schema/utils/testdb"
+
+	"github.com/golang/mock/gomock"
+)
+
+func TestSchema_Validate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	checksum, err := sqlfile.Checksum("./testdata/unit/002.sql")
+	if err != nil {
+		t.Fatalf("failed to calculate checksum: %s", err)
+	}
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+
+	mockScripter := schema_mock.NewMockScripter(ctrl)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(store.SchemaScriptCollection{
+		&store.SchemaScript{ScriptName: "001.sql", Status: store.StatusSuccess, Checksum: "modified"},
+		&store.SchemaScript{ScriptName: "002.sql", Status: store.StatusSuccess, Checksum: checksum},
+		&store.SchemaScript{ScriptName: "003.sql", Status: store.StatusSuccess},
+		&store.SchemaScript{ScriptName: "004.sql", Status: store.StatusError},
+	}, nil)
+
+	s := newSchema(t, mockDB, schema.WithScripter(mockScripter))
+
+	validation, err := s.Validate("./testdata/unit")
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	expected := map[string][]string{
+		schema.ProblemModified: {"001.sql"},
+		schema.ProblemMissing:  {"003.sql"},
+		schema.ProblemPending:  {},
+	}
+
+	for kind, scripts := range expected {
+		if actual := validation.Scripts(kind); !array.StringArrayEquals(scripts, actual) {
+			t.Errorf("Expected %s scripts %v but got %v", kind, scripts, actual)
+		}
+	}
+
+	if err = validation.Err(); !errors.Is(err, schema.ErrChecksumMismatch) {
+		t.Errorf("Expected error %s but got %v", schema.ErrChecksumMismatch, err)
+	}
+}
+
+func TestSchema_Validate_NewDatabase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDB := getMockDB(ctrl, false)
+	mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(errors.New("no table")) // nolint: goerr113
+
+	mockScripter := schema_mock.NewMockScripter(ctrl)
+	mockScripter.EXPECT().GetAllContext(gomock.Any()).Times(0)
+
+	s := newSchema(t, mockDB, schema.WithScripter(mockScripter))
+
+	validation, err := s.Validate("./testdata/unit")
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	expected := []string{"001.sql", "002.sql"}
+	if actual := validation.Scripts(schema.ProblemPending); !array.StringArrayEquals(expected, actual) {
+		t.Errorf("Expected pending scripts %v but got %v", expected, actual)
+	}
+
+	if err = validation.Err(); err != nil {
+		t.Errorf("Expected no error for pending scripts but got %s", err)
+	}
+}
+
+func TestSchema_Upgrade_Validation(t *testing.T) {
+	testCases := []struct {
+		name           string
+		skipValidation bool
+	}{
+		{
+			name: "fails on modified script",
+		},
+		{
+			name:           "validation deactivated",
+			skipValidation: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		skipValidation := testCase.skipValidation
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDB := getMockDB(ctrl, false)
+			mockDB.EXPECT().Select(gomock.Any(), gomock.Any()).Return(nil)
+
+			mockScripter := schema_mock.NewMockScripter(ctrl)
+			mockScripter.EXPECT().GetAllContext(gomock.Any()).Return(store.SchemaScriptCollection{
+				&store.SchemaScript{ScriptName: "001.sql", Status: store.StatusSuccess, Checksum: "modified"},
+			}, nil)
+
+			mockApplier := schema_mock.NewMockApplier(ctrl)
+
+			opts := []schema.Option{schema.WithApplier(mockApplier), schema.WithScripter(mockScripter)}
+
+			if skipValidation {
+				opts = append(opts, schema.WithoutValidation())
+				mockApplier.EXPECT().ApplyScriptFSContext(gomock.Any(), gomock.Any(), "002.sql", gomock.Any()).Return(nil)
+			} else {
+				mockApplier.EXPECT().ApplyScriptFSContext(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			}
+
+			s := newSchema(t, mockDB, opts...)
+
+			err := s.Upgrade("./testdata/unit", "")
+			if skipValidation && err != nil {
+				t.Errorf("Expected no error but got %s", err)
+			}
+
+			if !skipValidation && !errors.Is(err, schema.ErrChecksumMismatch) {
+				t.Errorf("Expected error %s but got %v", schema.ErrChecksumMismatch, err)
+			}
+		})
+	}
+}
+
+func TestSchema_Validate_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_validate.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	dir := t.TempDir()
+	if err = osutils.CopyFile("./testdata/upgrade/step1/001.sql", dir+"/001.sql"); err != nil {
+		t.Fatalf("failed to copy file: %s", err)
+	}
+
+	s := newSchema(t, db)
+	if err = s.Upgrade(dir, ""); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	validation, err := s.Validate(dir)
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if validation.Len() != 0 {
+		t.Fatalf("Expected no problems after upgrade but got %d", validation.Len())
+	}
+
+	content := "-- up\nCREATE TABLE IF NOT EXISTS changed(id INTEGER);\n-- down\nDROP TABLE IF EXISTS changed;\n"
+	if err = os.WriteFile(dir+"/001.sql", []byte(content), 0600); err != nil {
+		t.Fatalf("failed to modify file: %s", err)
+	}
+
+	validation, err = s.Validate(dir)
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if actual := validation.Scripts(schema.ProblemModified); len(actual) != 1 {
+		t.Errorf("Expected that modified script is reported but got %v", actual)
+	}
+
+	if err = s.Upgrade(dir, ""); !errors.Is(err, schema.ErrChecksumMismatch) {
+		t.Errorf("Expected error %s but got %v", schema.ErrChecksumMismatch, err)
+	}
+}
+
+func TestSchema_Upgrade_Integration_FormerTableLayout(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipped because of long running")
+	}
+
+	db, err := testdb.GetDB("./testdata/tmp/schema_former_layout.db")
+	if err != nil {
+		t.Fatalf("failed to init database: %s", err)
+	}
+
+	defer testdb.ShutdownDB(db, t)
+
+	_, err = db.Exec(`CREATE TABLE schema_script (
+		id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+		script_name TEXT NOT NULL,
+		executed_at DATETIME NOT NULL,
+		execution_status VARCHAR(100) NOT NULL,
+		app_version CHAR(30) NULL,
+		error_msg TEXT NULL
+	);`)
+	if err != nil {
+		t.Fatalf("failed to create table of a former version: %s", err)
+	}
+
+	dir := t.TempDir()
+	if err = osutils.CopyFile("./testdata/upgrade/step1/001.sql", dir+"/001.sql"); err != nil {
+		t.Fatalf("failed to copy file: %s", err)
+	}
+
+	s := newSchema(t, db)
+	if err = s.Upgrade(dir, ""); err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	validation, err := s.Validate(dir)
+	if err != nil {
+		t.Fatalf("Expected no error but got %s", err)
+	}
+
+	if validation.Len() != 0 {
+		t.Errorf("Expected no problems after upgrade but got %d", validation.Len())
+	}
+}